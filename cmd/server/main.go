@@ -13,8 +13,11 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/auth"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/discovery"
 	httpAdapter "github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/http"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/upstream"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
@@ -28,43 +31,85 @@ func main() {
 	// Initialize logger
 	logger := logger.NewLogger(cfg.Logging.Level, cfg.Logging.Format, "api-gateway")
 
-	// Log JWT configuration for debugging
-	jwtSecretPreview := "NOT_SET"
-	if cfg.Auth.JWTSecret != "" {
-		if len(cfg.Auth.JWTSecret) > 20 {
-			jwtSecretPreview = cfg.Auth.JWTSecret[:20] + "..."
-		} else {
-			jwtSecretPreview = cfg.Auth.JWTSecret
-		}
-	}
-
 	logger.Info("Starting Rootly API Gateway", map[string]interface{}{
 		"version": "1.0.0",
 		"port":    cfg.Server.Port,
 		"routes":  len(cfg.Routes),
 	})
 
-	logger.Info("🔐 JWT Configuration", map[string]interface{}{
-		"jwt_secret_preview": jwtSecretPreview,
-		"jwt_secret_length":  len(cfg.Auth.JWTSecret),
-		"jwt_expiration":     cfg.Auth.JWTExpiration,
-		"api_key_header":     cfg.Auth.APIKeyHeader,
-	})
-
 	// Initialize HTTP client
 	httpClient := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
+	// Load the key set for asymmetrically signed tokens (RS256, ES256, EdDSA)
+	var jwks *auth.JWKS
+	if cfg.Auth.JWKS != nil {
+		var err error
+		if jwks, err = auth.NewJWKS(*cfg.Auth.JWKS, logger); err != nil {
+			logger.Error("Invalid JWKS configuration", err, nil)
+			os.Exit(1)
+		}
+		defer jwks.Stop()
+	}
+
+	// Load the HMAC key ring (named keys from files or env, or the legacy jwt_secret)
+	keyRing, err := auth.NewKeyRing(cfg.Auth)
+	if err != nil {
+		logger.Error("Invalid signing key configuration", err, nil)
+		os.Exit(1)
+	}
+
+	// Local validation trusts any token signed with the ring's keys, so they must be private
+	if err := auth.CheckLocalValidationSecret(cfg.Auth.ValidationStrategy, keyRing); err != nil {
+		logger.Error("Insecure token validation configuration", err, nil)
+		os.Exit(1)
+	}
+	// Only key IDs and states are logged; secrets never leave the ring
+	logger.Info("🔐 JWT Configuration", map[string]interface{}{
+		"keys":                keyRing.Describe(),
+		"validation_strategy": cfg.Auth.ValidationStrategy,
+		"jwt_expiration":      cfg.Auth.JWTExpiration,
+		"api_key_header":      cfg.Auth.APIKeyHeader,
+	})
+
 	// Initialize auth service
 	authService := auth.NewAuthService(
-		cfg.Auth.JWTSecret,
+		keyRing,
 		cfg.Auth.JWTExpiration,
+		jwks,
+		cfg.Auth.Claims,
 		logger,
 	)
 
+	// Initialize metrics collector
+	metricsCollector := metrics.NewCollector()
+
+	// Initialize service discovery (static, DNS or endpoints file per service)
+	discoveryRegistry := discovery.NewRegistry(logger)
+	if err := discoveryRegistry.Update(cfg.Services); err != nil {
+		logger.Error("Invalid service discovery configuration", err, nil)
+		os.Exit(1)
+	}
+	defer discoveryRegistry.Stop()
+
+	// Initialize fault injection for chaos testing (switchable through the admin API)
+	faultInjector := services.NewFaultInjector(cfg.FaultInjection.Enabled, metricsCollector, logger)
+
+	// Initialize upstream transports (TLS settings, health checks and load balancing per service)
+	transportRegistry := upstream.NewTransportRegistry(discoveryRegistry, faultInjector, metricsCollector, logger)
+	if err := transportRegistry.Update(cfg.Services); err != nil {
+		logger.Error("Invalid upstream TLS configuration", err, nil)
+		os.Exit(1)
+	}
+	defer transportRegistry.Stop()
+
 	// Initialize config provider
-	configProvider := httpAdapter.NewConfigProvider(cfg, logger)
+	configProvider := httpAdapter.NewConfigProvider(cfg, discoveryRegistry, transportRegistry, logger)
+	if err := configProvider.ValidateRoutes(); err != nil {
+		logger.Error("Invalid route configuration", err, nil)
+		os.Exit(1)
+	}
 
 	// Initialize strategy manager
 	strategyManager := services.NewStrategyManager(logger)
@@ -72,20 +117,39 @@ func main() {
 	// Register strategies
 	registerStrategies(strategyManager, logger)
 
-	// Initialize gateway service
+	// Initialize gateway service; it only checks tokens itself when the gateway holds the keys
+	localTokenCheck := cfg.Auth.ValidationStrategy == auth.ValidationLocal || cfg.Auth.ValidationStrategy == auth.ValidationHybrid
 	gatewayService := services.NewGatewayService(
 		strategyManager,
 		nil, // Service orchestrator - could be implemented separately
 		authService,
+		localTokenCheck,
 		logger,
 		httpClient,
 		configProvider,
+		metricsCollector,
 	)
 
-	// Initialize HTTP handler
+	// Initialize HTTP handlers
 	gatewayHandler := httpAdapter.NewGatewayHandler(
 		gatewayService,
 		configProvider,
+		transportRegistry.HealthChecker(),
+		metricsCollector,
+		logger,
+	)
+
+	adminKey, err := httpAdapter.LoadAdminKey(cfg.Admin)
+	if err != nil {
+		logger.Error("Invalid admin API configuration", err, nil)
+		os.Exit(1)
+	}
+	adminHandler := httpAdapter.NewAdminHandler(
+		gatewayService,
+		configProvider,
+		faultInjector,
+		adminKey,
+		cfg.Admin.Header,
 		logger,
 	)
 
@@ -132,11 +196,52 @@ func main() {
 		"allow_credentials": corsConfig.AllowCredentials,
 	})
 
+	// Normalise paths before anything matches them against routes
+	pathNormalizer := httpAdapter.NewPathNormalizer(cfg.Server.TrailingSlash, logger)
+	router.Use(pathNormalizer.Middleware())
+
+	// Shed lower-priority routes first when the gateway itself is overloaded
+	if cfg.Server.LoadShedding.Enabled {
+		loadShedder := httpAdapter.NewLoadShedder(cfg.Server.LoadShedding, configProvider, metricsCollector, logger)
+		router.Use(loadShedder.Middleware())
+
+		logger.Info("Load shedding configured", map[string]interface{}{
+			"max_in_flight":  cfg.Server.LoadShedding.MaxInFlight,
+			"max_goroutines": cfg.Server.LoadShedding.MaxGoroutines,
+			"target_latency": cfg.Server.LoadShedding.TargetLatency.String(),
+		})
+	}
+
+	// Enforce request size limits before any body is read
+	requestLimiter := httpAdapter.NewRequestLimiter(
+		int64(cfg.Server.MaxBodySize),
+		cfg.Server.MaxJSONDepth,
+		configProvider,
+		logger,
+	)
+	router.Use(requestLimiter.Middleware())
+
+	logger.Info("Request limits configured", map[string]interface{}{
+		"max_body_size":    int64(cfg.Server.MaxBodySize),
+		"max_header_bytes": int64(cfg.Server.MaxHeaderBytes),
+		"max_json_depth":   cfg.Server.MaxJSONDepth,
+		"trailing_slash":   cfg.Server.TrailingSlash,
+	})
+
+	// Cache auth service validations so each token is checked once per TTL
+	var tokenCache *auth.TokenCache
+	if cfg.Auth.ValidationCache.Enabled {
+		tokenCache = auth.NewTokenCache(cfg.Auth.ValidationCache.TTL, cfg.Auth.ValidationCache.MaxEntries, metricsCollector)
+	}
+
 	// Setup JWT middleware for authentication
 	jwtMiddleware := auth.NewJWTMiddleware(
 		cfg.Services["auth"].URL,
 		cfg.Auth.ValidationEndpoint,
 		cfg.Auth.ValidationStrategy,
+		cfg.Auth.LogoutEndpoint,
+		authService,
+		tokenCache,
 		logger,
 		configProvider,
 	)
@@ -146,18 +251,21 @@ func main() {
 		"auth_service_url":    cfg.Services["auth"].URL,
 		"validation_endpoint": cfg.Auth.ValidationEndpoint,
 		"validation_strategy": cfg.Auth.ValidationStrategy,
+		"validation_cache":    cfg.Auth.ValidationCache.Enabled,
 		"jwt_expiration":      cfg.Auth.JWTExpiration,
 	})
 
 	// Register routes
+	adminHandler.RegisterRoutes(router)
 	gatewayHandler.RegisterRoutes(router)
 
 	// Setup server
 	server := &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
-		Handler:      router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
+		Addr:           fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
+		Handler:        router,
+		ReadTimeout:    cfg.Server.ReadTimeout,
+		WriteTimeout:   cfg.Server.WriteTimeout,
+		MaxHeaderBytes: int(cfg.Server.MaxHeaderBytes),
 	}
 
 	// Start server in a goroutine
@@ -186,10 +294,17 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...", nil)
+	// Fail readiness first and keep serving while load balancers notice
+	gatewayHandler.StartDraining()
+	server.SetKeepAlivesEnabled(false)
+	logger.Info("Shutting down server...", map[string]interface{}{
+		"drain_delay": cfg.Server.Shutdown.DrainDelay.String(),
+		"timeout":     cfg.Server.Shutdown.Timeout.String(),
+	})
+	time.Sleep(cfg.Server.Shutdown.DrainDelay)
 
-	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Stop accepting connections and give in-flight requests time to complete
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.Shutdown.Timeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
@@ -7,19 +7,29 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
 )
 
 // AuthService implements authentication functionality
 type AuthService struct {
-	jwtSecret     string
+	keyRing       *KeyRing
 	jwtExpiration time.Duration
+	jwks          *JWKS           // verification keys for asymmetric tokens, nil when not configured
+	claimsPolicy  ports.ClaimsPolicy
 	apiKeys       map[string]bool // In production, this would be a database
 	logger        ports.Logger
 }
 
-// NewAuthService creates a new auth service
-func NewAuthService(jwtSecret string, jwtExpiration time.Duration, logger ports.Logger) *AuthService {
+// asymmetricMethods are the signing algorithms verified with JWKS keys
+var asymmetricMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// hmacMethods are the signing algorithms verified with the shared secret
+var hmacMethods = []string{"HS256", "HS384", "HS512"}
+
+// NewAuthService creates a new auth service. HMAC tokens are signed and verified
+// with the key ring; asymmetric tokens are accepted only when a JWKS key set is given.
+func NewAuthService(keyRing *KeyRing, jwtExpiration time.Duration, jwks *JWKS, claims config.ClaimsPolicyConfig, logger ports.Logger) *AuthService {
 	// Initialize with some default API keys for testing
 	apiKeys := map[string]bool{
 		"rootly-api-key-123":     true,
@@ -29,8 +39,10 @@ func NewAuthService(jwtSecret string, jwtExpiration time.Duration, logger ports.
 	}
 
 	return &AuthService{
-		jwtSecret:     jwtSecret,
+		keyRing:       keyRing,
 		jwtExpiration: jwtExpiration,
+		jwks:          jwks,
+		claimsPolicy:  claimsPolicyFromConfig(claims),
 		apiKeys:       apiKeys,
 		logger:        logger,
 	}
@@ -58,15 +70,17 @@ func (as *AuthService) ValidateAPIKey(ctx context.Context, apiKey string) (bool,
 	return valid, nil
 }
 
-// ValidateJWT validates a JWT token and returns user information
+// ValidateJWT validates a JWT token against the global claims policy and returns
+// user information. HMAC tokens are verified with the key ring; RSA, ECDSA and
+// EdDSA tokens with the JWKS key named by their kid.
 func (as *AuthService) ValidateJWT(ctx context.Context, tokenString string) (*ports.UserInfo, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(as.jwtSecret), nil
-	})
+	return as.ValidateJWTWithPolicy(ctx, tokenString, nil)
+}
+
+// ValidateJWTWithPolicy validates a JWT token against the global claims policy
+// with a route's own policy layered over it
+func (as *AuthService) ValidateJWTWithPolicy(ctx context.Context, tokenString string, routePolicy *ports.ClaimsPolicy) (*ports.UserInfo, error) {
+	token, err := as.parseJWT(tokenString)
 
 	if err != nil {
 		as.logger.Warn("JWT validation failed", map[string]interface{}{
@@ -84,6 +98,17 @@ func (as *AuthService) ValidateJWT(ctx context.Context, tokenString string) (*po
 		return nil, errors.New("invalid JWT claims")
 	}
 
+	if err := checkClaims(claims, mergeClaimsPolicy(as.claimsPolicy, routePolicy), time.Now()); err != nil {
+		fields := map[string]interface{}{"error": err.Error()}
+		var claimErr *ClaimError
+		if errors.As(err, &claimErr) {
+			fields["claim"] = claimErr.Claim
+			fields["reason"] = claimErr.Reason
+		}
+		as.logger.Warn("JWT claims rejected", fields)
+		return nil, fmt.Errorf("invalid JWT token: %w", err)
+	}
+
 	// Extract user information from claims
 	userInfo := &ports.UserInfo{
 		Metadata: make(map[string]interface{}),
@@ -127,6 +152,40 @@ func (as *AuthService) ValidateJWT(ctx context.Context, tokenString string) (*po
 	return userInfo, nil
 }
 
+// parseJWT parses a token and verifies its signature; claims are left to
+// checkClaims so they can be checked with clock skew. An HMAC token without a kid
+// is tried against each key the ring still accepts until one signature matches.
+func (as *AuthService) parseJWT(tokenString string) (*jwt.Token, error) {
+	methods := hmacMethods
+	if as.jwks != nil {
+		methods = append(append([]string{}, hmacMethods...), asymmetricMethods...)
+	}
+
+	// The candidate keys are resolved once, so a grace period ending between
+	// attempts cannot shrink the list being walked
+	now := time.Now()
+	var secrets [][]byte
+	for attempt := 0; ; attempt++ {
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return as.jwks.Key(kid, token.Method.Alg())
+			}
+			if secrets == nil {
+				var err error
+				if secrets, err = as.keyRing.verificationKeys(kid, now); err != nil {
+					return nil, err
+				}
+			}
+			return secrets[attempt], nil
+		}, jwt.WithValidMethods(methods), jwt.WithoutClaimsValidation())
+
+		if err == nil || !errors.Is(err, jwt.ErrSignatureInvalid) || attempt+1 >= len(secrets) {
+			return token, err
+		}
+	}
+}
+
 // GenerateJWT generates a JWT token for the given user information
 func (as *AuthService) GenerateJWT(ctx context.Context, userInfo *ports.UserInfo) (string, error) {
 	// Create claims
@@ -144,11 +203,23 @@ func (as *AuthService) GenerateJWT(ctx context.Context, userInfo *ports.UserInfo
 		claims[key] = value
 	}
 
-	// Create token
+	// Tokens issued here must pass the gateway's own claims policy
+	if as.claimsPolicy.Issuer != "" {
+		claims["iss"] = as.claimsPolicy.Issuer
+	}
+	if len(as.claimsPolicy.Audiences) > 0 {
+		claims["aud"] = as.claimsPolicy.Audiences
+	}
+
+	// Create token, naming the active key so verifiers can pick it after a rotation
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	keyID, secret := as.keyRing.Active()
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
 
 	// Sign token
-	tokenString, err := token.SignedString([]byte(as.jwtSecret))
+	tokenString, err := token.SignedString(secret)
 	if err != nil {
 		as.logger.Error("Failed to generate JWT", err, map[string]interface{}{
 			"user_id": userInfo.ID,
@@ -191,10 +262,9 @@ func (as *AuthService) maskAPIKey(apiKey string) string {
 
 // RefreshJWT refreshes a JWT token if it's still valid but close to expiration
 func (as *AuthService) RefreshJWT(ctx context.Context, tokenString string) (string, error) {
-	// Parse the token without validation to check expiration
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(as.jwtSecret), nil
-	})
+	// Verify the token with any key the ring still accepts; the new one is signed
+	// with the active key
+	token, err := as.parseJWT(tokenString)
 
 	if err != nil {
 		return "", fmt.Errorf("failed to parse token for refresh: %w", err)
@@ -204,6 +274,9 @@ func (as *AuthService) RefreshJWT(ctx context.Context, tokenString string) (stri
 	if !ok {
 		return "", errors.New("invalid token claims")
 	}
+	if err := checkClaims(claims, as.claimsPolicy, time.Now()); err != nil {
+		return "", fmt.Errorf("token cannot be refreshed: %w", err)
+	}
 
 	// Check if token is close to expiration (within 1 hour)
 	if exp, ok := claims["exp"].(float64); ok {
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// ClaimError explains which claim made a token unacceptable and why
+type ClaimError struct {
+	Claim  string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("claim %s %s", e.Claim, e.Reason)
+}
+
+// checkClaims applies a claims policy to a token whose signature has been verified.
+// exp is always required; nbf and iat are checked whenever present. Every time
+// check allows the policy's clock skew.
+func checkClaims(claims jwt.MapClaims, policy ports.ClaimsPolicy, now time.Time) error {
+	skew := policy.ClockSkew
+
+	exp, present, err := timeClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !present {
+		return &ClaimError{Claim: "exp", Reason: "is missing"}
+	}
+	if !now.Before(exp.Add(skew)) {
+		return &ClaimError{Claim: "exp", Reason: fmt.Sprintf("expired at %s", exp.UTC().Format(time.RFC3339))}
+	}
+
+	nbf, present, err := timeClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if !present && policy.RequireNBF {
+		return &ClaimError{Claim: "nbf", Reason: "is missing"}
+	}
+	if present && now.Add(skew).Before(nbf) {
+		return &ClaimError{Claim: "nbf", Reason: fmt.Sprintf("not valid before %s", nbf.UTC().Format(time.RFC3339))}
+	}
+
+	iat, present, err := timeClaim(claims, "iat")
+	if err != nil {
+		return err
+	}
+	if !present && (policy.RequireIAT || policy.MaxAge > 0) {
+		return &ClaimError{Claim: "iat", Reason: "is missing"}
+	}
+	if present && now.Add(skew).Before(iat) {
+		return &ClaimError{Claim: "iat", Reason: fmt.Sprintf("issued in the future at %s", iat.UTC().Format(time.RFC3339))}
+	}
+	if present && policy.MaxAge > 0 && now.Sub(iat) > policy.MaxAge+skew {
+		return &ClaimError{Claim: "iat", Reason: fmt.Sprintf("is older than %s", policy.MaxAge)}
+	}
+
+	if policy.Issuer != "" {
+		iss, _ := claims["iss"].(string)
+		if iss != policy.Issuer {
+			return &ClaimError{Claim: "iss", Reason: fmt.Sprintf("is %q, want %q", iss, policy.Issuer)}
+		}
+	}
+
+	if len(policy.Audiences) > 0 && !anyMatch(claimValues(claims["aud"]), policy.Audiences) {
+		return &ClaimError{Claim: "aud", Reason: fmt.Sprintf("does not include any of %s", strings.Join(policy.Audiences, ", "))}
+	}
+
+	for name, want := range policy.RequiredClaims {
+		value, present := claims[name]
+		if !present {
+			return &ClaimError{Claim: name, Reason: "is missing"}
+		}
+		if want != "" && !anyMatch(claimValues(value), []string{want}) {
+			return &ClaimError{Claim: name, Reason: fmt.Sprintf("does not match %q", want)}
+		}
+	}
+	return nil
+}
+
+// mergeClaimsPolicy layers a route's policy over the global one. The route's
+// issuer, audiences, max age and clock skew replace the global values when set;
+// requirements and required claims add up.
+func mergeClaimsPolicy(global ports.ClaimsPolicy, route *ports.ClaimsPolicy) ports.ClaimsPolicy {
+	if route == nil {
+		return global
+	}
+	merged := global
+	if route.Issuer != "" {
+		merged.Issuer = route.Issuer
+	}
+	if len(route.Audiences) > 0 {
+		merged.Audiences = route.Audiences
+	}
+	if route.MaxAge > 0 {
+		merged.MaxAge = route.MaxAge
+	}
+	if route.ClockSkew > 0 {
+		merged.ClockSkew = route.ClockSkew
+	}
+	merged.RequireNBF = global.RequireNBF || route.RequireNBF
+	merged.RequireIAT = global.RequireIAT || route.RequireIAT
+	if len(route.RequiredClaims) > 0 {
+		merged.RequiredClaims = make(map[string]string, len(global.RequiredClaims)+len(route.RequiredClaims))
+		for name, value := range global.RequiredClaims {
+			merged.RequiredClaims[name] = value
+		}
+		for name, value := range route.RequiredClaims {
+			merged.RequiredClaims[name] = value
+		}
+	}
+	return merged
+}
+
+// timeClaim reads a NumericDate claim
+func timeClaim(claims jwt.MapClaims, name string) (time.Time, bool, error) {
+	value, present := claims[name]
+	if !present {
+		return time.Time{}, false, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, true, &ClaimError{Claim: name, Reason: "is not a number"}
+	}
+	return time.Unix(int64(seconds), 0), true, nil
+}
+
+// claimValues returns a string or array claim as a list of strings
+func claimValues(value interface{}) []string {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{typed}
+	case []interface{}:
+		values := make([]string, 0, len(typed))
+		for _, item := range typed {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(typed)}
+	}
+}
+
+// anyMatch reports whether the two lists share a value
+func anyMatch(values, accepted []string) bool {
+	for _, value := range values {
+		for _, candidate := range accepted {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimsPolicyFromConfig converts the configured global claims policy
+func claimsPolicyFromConfig(cfg config.ClaimsPolicyConfig) ports.ClaimsPolicy {
+	return ports.ClaimsPolicy{
+		Issuer:         cfg.Issuer,
+		Audiences:      cfg.Audiences,
+		RequireNBF:     cfg.RequireNBF,
+		RequireIAT:     cfg.RequireIAT,
+		MaxAge:         cfg.MaxAge,
+		ClockSkew:      cfg.ClockSkew,
+		RequiredClaims: cfg.RequiredClaims,
+	}
+}
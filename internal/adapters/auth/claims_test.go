@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestCheckClaims(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	at := func(offset time.Duration) float64 { return float64(now.Add(offset).Unix()) }
+
+	tests := []struct {
+		name      string
+		claims    jwt.MapClaims
+		policy    ports.ClaimsPolicy
+		wantClaim string // empty when the token is accepted
+	}{
+		{
+			name:   "valid expiry",
+			claims: jwt.MapClaims{"exp": at(time.Hour)},
+		},
+		{
+			name:      "missing exp",
+			claims:    jwt.MapClaims{},
+			wantClaim: "exp",
+		},
+		{
+			name:      "expired",
+			claims:    jwt.MapClaims{"exp": at(-time.Minute)},
+			wantClaim: "exp",
+		},
+		{
+			name:   "expired within clock skew",
+			claims: jwt.MapClaims{"exp": at(-10 * time.Second)},
+			policy: ports.ClaimsPolicy{ClockSkew: 30 * time.Second},
+		},
+		{
+			name:      "exp not a number",
+			claims:    jwt.MapClaims{"exp": "tomorrow"},
+			wantClaim: "exp",
+		},
+		{
+			name:      "not valid yet",
+			claims:    jwt.MapClaims{"exp": at(time.Hour), "nbf": at(time.Minute)},
+			wantClaim: "nbf",
+		},
+		{
+			name:   "nbf within clock skew",
+			claims: jwt.MapClaims{"exp": at(time.Hour), "nbf": at(10 * time.Second)},
+			policy: ports.ClaimsPolicy{ClockSkew: 30 * time.Second},
+		},
+		{
+			name:      "required nbf missing",
+			claims:    jwt.MapClaims{"exp": at(time.Hour)},
+			policy:    ports.ClaimsPolicy{RequireNBF: true},
+			wantClaim: "nbf",
+		},
+		{
+			name:      "issued in the future",
+			claims:    jwt.MapClaims{"exp": at(time.Hour), "iat": at(time.Minute)},
+			wantClaim: "iat",
+		},
+		{
+			name:      "max age needs iat",
+			claims:    jwt.MapClaims{"exp": at(time.Hour)},
+			policy:    ports.ClaimsPolicy{MaxAge: time.Hour},
+			wantClaim: "iat",
+		},
+		{
+			name:      "older than max age",
+			claims:    jwt.MapClaims{"exp": at(time.Hour), "iat": at(-2 * time.Hour)},
+			policy:    ports.ClaimsPolicy{MaxAge: time.Hour},
+			wantClaim: "iat",
+		},
+		{
+			name:   "within max age",
+			claims: jwt.MapClaims{"exp": at(time.Hour), "iat": at(-30 * time.Minute)},
+			policy: ports.ClaimsPolicy{MaxAge: time.Hour},
+		},
+		{
+			name:      "wrong issuer",
+			claims:    jwt.MapClaims{"exp": at(time.Hour), "iss": "someone-else"},
+			policy:    ports.ClaimsPolicy{Issuer: "rootly-auth"},
+			wantClaim: "iss",
+		},
+		{
+			name:   "audience string",
+			claims: jwt.MapClaims{"exp": at(time.Hour), "aud": "rootly-gateway"},
+			policy: ports.ClaimsPolicy{Audiences: []string{"rootly-gateway"}},
+		},
+		{
+			name:   "audience array",
+			claims: jwt.MapClaims{"exp": at(time.Hour), "aud": []interface{}{"other", "rootly-gateway"}},
+			policy: ports.ClaimsPolicy{Audiences: []string{"rootly-gateway", "rootly-admin"}},
+		},
+		{
+			name:      "audience not accepted",
+			claims:    jwt.MapClaims{"exp": at(time.Hour), "aud": []interface{}{"other"}},
+			policy:    ports.ClaimsPolicy{Audiences: []string{"rootly-gateway"}},
+			wantClaim: "aud",
+		},
+		{
+			name:      "required claim missing",
+			claims:    jwt.MapClaims{"exp": at(time.Hour)},
+			policy:    ports.ClaimsPolicy{RequiredClaims: map[string]string{"tenant": ""}},
+			wantClaim: "tenant",
+		},
+		{
+			name:   "required claim present",
+			claims: jwt.MapClaims{"exp": at(time.Hour), "tenant": "farm-1"},
+			policy: ports.ClaimsPolicy{RequiredClaims: map[string]string{"tenant": ""}},
+		},
+		{
+			name:      "required claim value mismatch",
+			claims:    jwt.MapClaims{"exp": at(time.Hour), "scope": []interface{}{"read"}},
+			policy:    ports.ClaimsPolicy{RequiredClaims: map[string]string{"scope": "write"}},
+			wantClaim: "scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkClaims(tt.claims, tt.policy, now)
+			if tt.wantClaim == "" {
+				if err != nil {
+					t.Errorf("checkClaims() error = %v, want nil", err)
+				}
+				return
+			}
+			var claimErr *ClaimError
+			if !errors.As(err, &claimErr) || claimErr.Claim != tt.wantClaim {
+				t.Errorf("checkClaims() error = %v, want a %s claim error", err, tt.wantClaim)
+			}
+		})
+	}
+}
+
+func TestMergeClaimsPolicy(t *testing.T) {
+	global := ports.ClaimsPolicy{
+		Issuer:         "rootly-auth",
+		Audiences:      []string{"rootly-gateway"},
+		RequireIAT:     true,
+		ClockSkew:      30 * time.Second,
+		RequiredClaims: map[string]string{"tenant": ""},
+	}
+
+	tests := []struct {
+		name  string
+		route *ports.ClaimsPolicy
+		want  ports.ClaimsPolicy
+	}{
+		{
+			name: "no route policy",
+			want: global,
+		},
+		{
+			name:  "route replaces audiences and adds max age",
+			route: &ports.ClaimsPolicy{Audiences: []string{"rootly-admin"}, MaxAge: 15 * time.Minute},
+			want: ports.ClaimsPolicy{
+				Issuer:         "rootly-auth",
+				Audiences:      []string{"rootly-admin"},
+				RequireIAT:     true,
+				MaxAge:         15 * time.Minute,
+				ClockSkew:      30 * time.Second,
+				RequiredClaims: map[string]string{"tenant": ""},
+			},
+		},
+		{
+			name:  "requirements and required claims add up",
+			route: &ports.ClaimsPolicy{RequireNBF: true, RequiredClaims: map[string]string{"scope": "admin"}},
+			want: ports.ClaimsPolicy{
+				Issuer:         "rootly-auth",
+				Audiences:      []string{"rootly-gateway"},
+				RequireNBF:     true,
+				RequireIAT:     true,
+				ClockSkew:      30 * time.Second,
+				RequiredClaims: map[string]string{"tenant": "", "scope": "admin"},
+			},
+		},
+		{
+			name:  "route cannot drop a global requirement",
+			route: &ports.ClaimsPolicy{Issuer: "rootly-admin-auth", ClockSkew: time.Minute},
+			want: ports.ClaimsPolicy{
+				Issuer:         "rootly-admin-auth",
+				Audiences:      []string{"rootly-gateway"},
+				RequireIAT:     true,
+				ClockSkew:      time.Minute,
+				RequiredClaims: map[string]string{"tenant": ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeClaimsPolicy(global, tt.route); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeClaimsPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if len(global.RequiredClaims) != 1 {
+		t.Errorf("merging changed the global required claims: %v", global.RequiredClaims)
+	}
+}
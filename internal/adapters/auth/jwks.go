@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+const (
+	defaultJWKSRefresh = 10 * time.Minute
+	// minJWKSRefetch bounds how often an unknown kid can trigger a fetch, so tokens
+	// with made-up key IDs cannot flood the key source
+	minJWKSRefetch = 10 * time.Second
+	maxJWKSSize    = 1 << 20
+)
+
+// JWKS holds the verification keys published in a JSON Web Key Set, read from a
+// URL or a local file. Keys are looked up by kid, refreshed in the background and
+// refetched when a token names a kid the set does not contain yet, so the auth
+// service can rotate keys without a gateway redeploy.
+type JWKS struct {
+	url        string
+	file       string
+	refresh    time.Duration
+	keys       map[string]jwksKey
+	fetchedAt  time.Time
+	mutex      sync.RWMutex
+	fetchMutex sync.Mutex
+	httpClient *http.Client
+	done       chan struct{}
+	once       sync.Once
+	logger     ports.Logger
+}
+
+// jwksKey is one parsed verification key and the algorithm it is restricted to, if any
+type jwksKey struct {
+	key interface{}
+	alg string
+}
+
+// jsonWebKey is a key as published in a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWKS loads the key set and starts refreshing it in the background. A URL
+// that cannot be reached yet is retried later; a missing file is an error.
+func NewJWKS(cfg config.JWKSConfig, logger ports.Logger) (*JWKS, error) {
+	if (cfg.URL == "") == (cfg.File == "") {
+		return nil, errors.New("jwks needs exactly one of url or file")
+	}
+
+	j := &JWKS{
+		url:        cfg.URL,
+		file:       cfg.File,
+		refresh:    cfg.RefreshInterval,
+		keys:       make(map[string]jwksKey),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		done:       make(chan struct{}),
+		logger:     logger,
+	}
+	if j.refresh <= 0 {
+		j.refresh = defaultJWKSRefresh
+	}
+
+	if err := j.load(); err != nil {
+		if j.file != "" {
+			return nil, err
+		}
+		logger.Warn("⚠️ JWKS not loaded yet, retrying in the background", map[string]interface{}{
+			"url":   j.url,
+			"error": err.Error(),
+		})
+	}
+
+	go j.run()
+	return j, nil
+}
+
+// Key returns the verification key for a kid, refetching the set once when the
+// kid is unknown. A token without a kid is accepted only when the set holds a
+// single key.
+func (j *JWKS) Key(kid, alg string) (interface{}, error) {
+	key, found := j.lookup(kid)
+	if !found && kid != "" && j.refetch() {
+		key, found = j.lookup(kid)
+	}
+	if !found {
+		return nil, fmt.Errorf("no JWKS key for kid %q", kid)
+	}
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("JWKS key %q is for %s, token uses %s", kid, key.alg, alg)
+	}
+	return key.key, nil
+}
+
+// Stop stops the background refresh
+func (j *JWKS) Stop() {
+	j.once.Do(func() { close(j.done) })
+}
+
+// lookup finds a key in the current set
+func (j *JWKS) lookup(kid string) (jwksKey, bool) {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	if kid == "" {
+		if len(j.keys) != 1 {
+			return jwksKey{}, false
+		}
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, found := j.keys[kid]
+	return key, found
+}
+
+// refetch reloads the set unless it was loaded very recently; it reports whether it did
+func (j *JWKS) refetch() bool {
+	j.fetchMutex.Lock()
+	defer j.fetchMutex.Unlock()
+
+	j.mutex.RLock()
+	recent := time.Since(j.fetchedAt) < minJWKSRefetch
+	j.mutex.RUnlock()
+	if recent {
+		return false
+	}
+	if err := j.load(); err != nil {
+		j.logger.Warn("JWKS refetch failed", map[string]interface{}{
+			"source": j.source(),
+			"error":  err.Error(),
+		})
+	}
+	return true
+}
+
+// run refreshes the set until stopped
+func (j *JWKS) run() {
+	ticker := time.NewTicker(j.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.done:
+			return
+		case <-ticker.C:
+			j.fetchMutex.Lock()
+			if err := j.load(); err != nil {
+				j.logger.Warn("JWKS refresh failed, keeping previous keys", map[string]interface{}{
+					"source": j.source(),
+					"error":  err.Error(),
+				})
+			}
+			j.fetchMutex.Unlock()
+		}
+	}
+}
+
+// load reads and parses the key set and replaces the current keys. A failed load
+// keeps the previous keys but still counts as a fetch for rate limiting.
+func (j *JWKS) load() error {
+	data, err := j.read()
+	if err == nil {
+		var keys map[string]jwksKey
+		if keys, err = parseJWKS(data); err == nil {
+			j.mutex.Lock()
+			j.keys = keys
+			j.fetchedAt = time.Now()
+			j.mutex.Unlock()
+
+			j.logger.Info("🔑 JWKS loaded", map[string]interface{}{
+				"source": j.source(),
+				"keys":   len(keys),
+			})
+			return nil
+		}
+	}
+
+	j.mutex.Lock()
+	j.fetchedAt = time.Now()
+	j.mutex.Unlock()
+	return err
+}
+
+// read returns the raw JWKS document
+func (j *JWKS) read() ([]byte, error) {
+	if j.file != "" {
+		return os.ReadFile(j.file)
+	}
+
+	resp, err := j.httpClient.Get(j.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS fetch returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// source names where the set comes from, for logs
+func (j *JWKS) source() string {
+	if j.file != "" {
+		return j.file
+	}
+	return j.url
+}
+
+// parseJWKS parses the signing keys of a JWKS document. Keys of unsupported
+// types are skipped so one new key type does not break the whole set.
+func parseJWKS(data []byte) (map[string]jwksKey, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = jwksKey{key: key, alg: jwk.Alg}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS document has no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey decodes the key material for RSA, EC (P-256, P-384, P-521) and Ed25519 keys
+func (jwk jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64URL(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URL(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", jwk.Crv)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URL(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC key is not on its curve")
+		}
+		return key, nil
+
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", jwk.Crv)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+// decodeBase64URL decodes unpadded base64url, as used by JWK fields
+func decodeBase64URL(value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("missing key field")
+	}
+	return base64.RawURLEncoding.DecodeString(value)
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	rsaJWK := map[string]string{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())}
+	ecJWK := map[string]string{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())}
+	edJWK := map[string]string{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": encode(edKey)}
+
+	tests := []struct {
+		name     string
+		document string
+		keys     []map[string]string
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "all supported key types", keys: []map[string]string{rsaJWK, ecJWK, edJWK}, wantKids: []string{"rsa-1", "ec-1", "ed-1"}},
+		{name: "encryption keys skipped", keys: []map[string]string{rsaJWK, with(ecJWK, "use", "enc")}, wantKids: []string{"rsa-1"}},
+		{name: "unsupported key type skipped", keys: []map[string]string{{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}, edJWK}, wantKids: []string{"ed-1"}},
+		{name: "unsupported curve skipped", keys: []map[string]string{with(ecJWK, "crv", "P-192"), rsaJWK}, wantKids: []string{"rsa-1"}},
+		{name: "point off the curve skipped", keys: []map[string]string{with(ecJWK, "y", encode([]byte{1})), rsaJWK}, wantKids: []string{"rsa-1"}},
+		{name: "short Ed25519 key skipped", keys: []map[string]string{with(edJWK, "x", encode([]byte{1, 2, 3})), rsaJWK}, wantKids: []string{"rsa-1"}},
+		{name: "no usable keys", keys: []map[string]string{{"kty": "oct", "kid": "hmac"}}, wantErr: true},
+		{name: "empty set", keys: []map[string]string{}, wantErr: true},
+		{name: "invalid JSON", document: "{", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := []byte(tt.document)
+			if tt.document == "" {
+				if document, err = json.Marshal(map[string]interface{}{"keys": tt.keys}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			keys, err := parseJWKS(document)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJWKS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantKids) {
+				t.Errorf("parseJWKS() returned %d keys, want %v", len(keys), tt.wantKids)
+			}
+			for _, kid := range tt.wantKids {
+				if _, exists := keys[kid]; !exists {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+}
+
+// with returns a copy of a JWK with one field replaced
+func with(jwk map[string]string, field, value string) map[string]string {
+	copied := make(map[string]string, len(jwk))
+	for name, existing := range jwk {
+		copied[name] = existing
+	}
+	copied[field] = value
+	return copied
+}
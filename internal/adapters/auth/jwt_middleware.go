@@ -14,11 +14,46 @@ import (
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
 )
 
-// JWTMiddleware handles JWT token validation against the auth service
+// Token validation strategies
+const (
+	// ValidationService asks the auth service about every token
+	ValidationService = "service"
+	// ValidationLocal checks the signature and claims with the gateway's keys only
+	ValidationLocal = "local"
+	// ValidationHybrid checks locally first and asks the auth service only about
+	// tokens that pass, so revoked tokens are still caught
+	ValidationHybrid = "hybrid"
+)
+
+// publishedSecrets are example secrets that have been committed to this
+// repository; a token signed with one of them proves nothing
+var publishedSecrets = map[string]bool{
+	"test-jwt-secret-key-for-development-only-32-chars-minimum": true,
+	"your-super-secret-jwt-key-change-in-production":            true,
+}
+
+// CheckLocalValidationSecret refuses the local and hybrid strategies when tokens
+// would be accepted on the strength of a published example secret
+func CheckLocalValidationSecret(strategy string, keyRing *KeyRing) error {
+	if strategy != ValidationLocal && strategy != ValidationHybrid {
+		return nil
+	}
+	for secret := range publishedSecrets {
+		if keyRing.hasSecret(secret) {
+			return fmt.Errorf("%s token validation refuses a published example signing secret", strategy)
+		}
+	}
+	return nil
+}
+
+// JWTMiddleware handles JWT token validation against the auth service or locally
 type JWTMiddleware struct {
 	authServiceURL     string
 	validationEndpoint string
 	validationStrategy string
+	logoutEndpoint     string
+	authService        ports.AuthService
+	tokenCache         *TokenCache
 	httpClient         *http.Client
 	logger             ports.Logger
 	configProvider     ports.ConfigProvider
@@ -29,13 +64,28 @@ func NewJWTMiddleware(
 	authServiceURL string,
 	validationEndpoint string,
 	validationStrategy string,
+	logoutEndpoint string,
+	authService ports.AuthService,
+	tokenCache *TokenCache,
 	logger ports.Logger,
 	configProvider ports.ConfigProvider,
 ) *JWTMiddleware {
+	switch validationStrategy {
+	case ValidationService, ValidationLocal, ValidationHybrid:
+	default:
+		logger.Warn("Unknown token validation strategy, using service", map[string]interface{}{
+			"validation_strategy": validationStrategy,
+		})
+		validationStrategy = ValidationService
+	}
+
 	return &JWTMiddleware{
 		authServiceURL:     authServiceURL,
 		validationEndpoint: validationEndpoint,
 		validationStrategy: validationStrategy,
+		logoutEndpoint:     logoutEndpoint,
+		authService:        authService,
+		tokenCache:         tokenCache,
 		httpClient: &http.Client{
 			Timeout: 5 * time.Second,
 		},
@@ -53,6 +103,11 @@ func (m *JWTMiddleware) ValidateRequest() gin.HandlerFunc {
 			return
 		}
 
+		// A logged out token must not keep passing from the cache
+		if m.tokenCache != nil && c.Request.URL.Path == m.logoutEndpoint {
+			defer m.purgeToken(c)
+		}
+
 		// Get route configuration
 		routeConfig, found := m.configProvider.GetRouteConfig(c.Request.URL.Path, c.Request.Method)
 		
@@ -97,8 +152,8 @@ func (m *JWTMiddleware) ValidateRequest() gin.HandlerFunc {
 
 		token := parts[1]
 
-		// Validate token against auth service
-		user, err := m.validateToken(c.Request.Context(), token)
+		// Validate token with the configured strategy
+		user, err := m.validateToken(c.Request.Context(), token, routeConfig.Claims)
 		if err != nil {
 			m.logger.Warn("Token validation failed", map[string]interface{}{
 				"path":   c.Request.URL.Path,
@@ -134,11 +189,12 @@ type TokenValidationRequest struct {
 
 // TokenValidationResponse represents the response from token validation
 type TokenValidationResponse struct {
-	Valid   bool              `json:"valid"`
-	UserID  string            `json:"user_id,omitempty"`
-	Email   string            `json:"email,omitempty"`
-	Roles   []string          `json:"roles,omitempty"`
-	Message string            `json:"message,omitempty"`
+	Valid    bool                   `json:"valid"`
+	UserID   string                 `json:"user_id,omitempty"`
+	Email    string                 `json:"email,omitempty"`
+	Username string                 `json:"username,omitempty"`
+	Roles    []string               `json:"roles,omitempty"`
+	Message  string                 `json:"message,omitempty"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -151,8 +207,62 @@ type UserInfo struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// validateToken validates a JWT token against the auth service
-func (m *JWTMiddleware) validateToken(ctx context.Context, token string) (*UserInfo, error) {
+// validateToken validates a JWT token with the configured strategy; local checks
+// also apply the route's claims policy
+func (m *JWTMiddleware) validateToken(ctx context.Context, token string, claims *ports.ClaimsPolicy) (*UserInfo, error) {
+	switch m.validationStrategy {
+	case ValidationLocal:
+		return m.validateLocally(ctx, token, claims)
+	case ValidationHybrid:
+		// Forged and expired tokens are rejected without a call to the auth service
+		if _, err := m.validateLocally(ctx, token, claims); err != nil {
+			return nil, err
+		}
+		return m.validateCached(ctx, token)
+	default:
+		return m.validateCached(ctx, token)
+	}
+}
+
+// validateCached asks the auth service, reusing a recent answer for the same token
+func (m *JWTMiddleware) validateCached(ctx context.Context, token string) (*UserInfo, error) {
+	if m.tokenCache == nil {
+		return m.validateWithService(ctx, token)
+	}
+	return m.tokenCache.Validate(ctx, token, func(ctx context.Context) (*UserInfo, error) {
+		return m.validateWithService(ctx, token)
+	})
+}
+
+// purgeToken drops the request's bearer token from the validation cache
+func (m *JWTMiddleware) purgeToken(c *gin.Context) {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return
+	}
+	m.tokenCache.Purge(parts[1])
+	m.logger.Debug("Token purged from validation cache after logout", map[string]interface{}{
+		"path": c.Request.URL.Path,
+	})
+}
+
+// validateLocally checks the token signature and claims without calling the auth service
+func (m *JWTMiddleware) validateLocally(ctx context.Context, token string, claims *ports.ClaimsPolicy) (*UserInfo, error) {
+	user, err := m.authService.ValidateJWTWithPolicy(ctx, token, claims)
+	if err != nil {
+		return nil, err
+	}
+	return &UserInfo{
+		ID:       user.ID,
+		Email:    user.Email,
+		Username: user.Username,
+		Roles:    user.Roles,
+		Metadata: user.Metadata,
+	}, nil
+}
+
+// validateWithService validates a JWT token against the auth service
+func (m *JWTMiddleware) validateWithService(ctx context.Context, token string) (*UserInfo, error) {
 	// Prepare validation request
 	validationReq := TokenValidationRequest{
 		Token: token,
@@ -210,10 +320,15 @@ func (m *JWTMiddleware) validateToken(ctx context.Context, token string) (*UserI
 		return nil, fmt.Errorf("token is not valid: %s", validationResp.Message)
 	}
 
-	// Return user information
+	// Return user information in the same shape as local validation
+	username := validationResp.Username
+	if name, ok := validationResp.Metadata["username"].(string); ok && username == "" {
+		username = name
+	}
 	return &UserInfo{
 		ID:       validationResp.UserID,
 		Email:    validationResp.Email,
+		Username: username,
 		Roles:    validationResp.Roles,
 		Metadata: validationResp.Metadata,
 	}, nil
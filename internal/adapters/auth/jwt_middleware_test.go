@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+const middlewareSecret = "middleware-test-secret-0123456789abcdef"
+
+// protectedRoute is a config provider whose only route requires authentication
+type protectedRoute struct{}
+
+func (protectedRoute) GetRouteConfig(path string, method string) (*ports.RouteConfig, bool) {
+	if path != "/api/v1/plants" {
+		return nil, false
+	}
+	return &ports.RouteConfig{Path: path, Method: method, AuthRequired: true}, true
+}
+func (protectedRoute) GetServiceConfig(string) (*ports.ServiceInfo, bool)      { return nil, false }
+func (protectedRoute) ListServices() []string                                  { return nil }
+func (protectedRoute) GetStrategyConfig(string) (map[string]interface{}, bool) { return nil, false }
+func (protectedRoute) ReloadConfig() error                                     { return nil }
+
+func TestJWTMiddlewareStrategies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger("error", "json", "test")
+
+	signed := func(secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   "u1",
+			"roles": []string{"viewer"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	localToken := signed(middlewareSecret)
+	foreignToken := signed("a-key-only-the-auth-service-holds")
+
+	tests := []struct {
+		name         string
+		strategy     string
+		token        string
+		serviceValid bool
+		wantStatus   int
+		wantCalls    int32
+		wantRoles    []string
+	}{
+		{"service trusts the auth service", ValidationService, foreignToken, true, http.StatusOK, 1, []string{"admin"}},
+		{"service rejects what the auth service rejects", ValidationService, localToken, false, http.StatusUnauthorized, 1, nil},
+		{"local never calls the auth service", ValidationLocal, localToken, false, http.StatusOK, 0, []string{"viewer"}},
+		{"local rejects a foreign signature", ValidationLocal, foreignToken, true, http.StatusUnauthorized, 0, nil},
+		{"hybrid asks the auth service after a local pass", ValidationHybrid, localToken, true, http.StatusOK, 1, []string{"admin"}},
+		{"hybrid drops a forged token before the auth service", ValidationHybrid, foreignToken, true, http.StatusUnauthorized, 0, nil},
+		{"hybrid honours an auth service revocation", ValidationHybrid, localToken, false, http.StatusUnauthorized, 1, nil},
+		{"unknown strategy falls back to service", "eventual", foreignToken, true, http.StatusOK, 1, []string{"admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				json.NewEncoder(w).Encode(TokenValidationResponse{Valid: tt.serviceValid, UserID: "u1", Roles: []string{"admin"}})
+			}))
+			defer authServer.Close()
+
+			keyRing, err := NewKeyRing(config.AuthConfig{JWTSecret: middlewareSecret})
+			if err != nil {
+				t.Fatal(err)
+			}
+			authService := NewAuthService(keyRing, time.Hour, nil, config.ClaimsPolicyConfig{}, log)
+			middleware := NewJWTMiddleware(authServer.URL, "/validate", tt.strategy, "/logout", authService, nil, log, protectedRoute{})
+
+			var gotRoles []string
+			router := gin.New()
+			router.Use(middleware.ValidateRequest())
+			router.GET("/api/v1/plants", func(c *gin.Context) {
+				if user, ok := c.Get("user"); ok {
+					gotRoles = user.(*UserInfo).Roles
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/api/v1/plants", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("auth service calls = %d, want %d", got, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(gotRoles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", gotRoles, tt.wantRoles)
+			}
+		})
+	}
+}
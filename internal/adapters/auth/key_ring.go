@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+)
+
+// Signing key states
+const (
+	// KeyActive signs new tokens and verifies existing ones
+	KeyActive = "active"
+	// KeyVerifyOnly verifies tokens until its grace period ends
+	KeyVerifyOnly = "verify_only"
+	// KeyRetired no longer verifies anything; tokens naming it are rejected
+	KeyRetired = "retired"
+)
+
+// KeyRing holds the named HMAC keys used for locally issued and validated tokens.
+// One key is active and signs every new token; verify-only keys keep accepting
+// tokens signed before a rotation until their grace period ends.
+type KeyRing struct {
+	keys   map[string]*signingKey
+	active *signingKey
+}
+
+// signingKey is one HMAC secret and its rotation state
+type signingKey struct {
+	id         string
+	status     string
+	secret     []byte
+	graceUntil time.Time
+}
+
+// NewKeyRing builds the key ring from the configured keys, reading each secret
+// from its file or environment variable. Without configured keys the legacy
+// jwt_secret, from JWT_SECRET_KEY or jwt_secret_file, becomes the only, unnamed,
+// active key. A secret written in the config file itself is refused.
+func NewKeyRing(cfg config.AuthConfig) (*KeyRing, error) {
+	if cfg.JWTSecretFromYAML {
+		return nil, errors.New("jwt_secret must not be set in the config file; use JWT_SECRET_KEY or jwt_secret_file")
+	}
+
+	kr := &KeyRing{keys: make(map[string]*signingKey)}
+	if len(cfg.SigningKeys) == 0 {
+		secret, err := legacySecret(cfg)
+		if err != nil {
+			return nil, err
+		}
+		kr.active = &signingKey{status: KeyActive, secret: secret}
+		kr.keys[""] = kr.active
+		return kr, nil
+	}
+
+	for _, keyConfig := range cfg.SigningKeys {
+		if keyConfig.ID == "" {
+			return nil, errors.New("signing key without an id")
+		}
+		if _, exists := kr.keys[keyConfig.ID]; exists {
+			return nil, fmt.Errorf("duplicate signing key id %q", keyConfig.ID)
+		}
+		switch keyConfig.Status {
+		case KeyActive, KeyVerifyOnly, KeyRetired:
+		default:
+			return nil, fmt.Errorf("signing key %s: unknown status %q", keyConfig.ID, keyConfig.Status)
+		}
+
+		key := &signingKey{id: keyConfig.ID, status: keyConfig.Status, graceUntil: keyConfig.GraceUntil}
+		if keyConfig.Status != KeyRetired {
+			secret, err := readSecret(keyConfig)
+			if err != nil {
+				return nil, fmt.Errorf("signing key %s: %w", keyConfig.ID, err)
+			}
+			key.secret = secret
+		}
+		if key.status == KeyActive {
+			if kr.active != nil {
+				return nil, fmt.Errorf("signing keys %s and %s are both active", kr.active.id, key.id)
+			}
+			kr.active = key
+		}
+		kr.keys[key.id] = key
+	}
+	if kr.active == nil {
+		return nil, errors.New("no active signing key")
+	}
+	return kr, nil
+}
+
+// Active returns the ID and secret of the key that signs new tokens
+func (kr *KeyRing) Active() (string, []byte) {
+	return kr.active.id, kr.active.secret
+}
+
+// Describe lists the keys and their states, without secrets, for logs
+func (kr *KeyRing) Describe() map[string]string {
+	states := make(map[string]string, len(kr.keys))
+	for id, key := range kr.keys {
+		if id == "" {
+			id = "jwt_secret"
+		}
+		states[id] = key.status
+	}
+	return states
+}
+
+// verificationKeys returns the secrets that may have signed a token. A token
+// naming a key is checked against that key only; a token without a kid is tried
+// against the active key and then every verify-only key still in its grace period.
+func (kr *KeyRing) verificationKeys(kid string, now time.Time) ([][]byte, error) {
+	if kid != "" {
+		key, exists := kr.keys[kid]
+		if !exists {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if !key.verifies(now) {
+			return nil, fmt.Errorf("signing key %q is no longer accepted", kid)
+		}
+		return [][]byte{key.secret}, nil
+	}
+
+	secrets := [][]byte{kr.active.secret}
+	ids := make([]string, 0, len(kr.keys))
+	for id, key := range kr.keys {
+		if key.status == KeyVerifyOnly && key.verifies(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		secrets = append(secrets, kr.keys[id].secret)
+	}
+	return secrets, nil
+}
+
+// verifies reports whether the key still accepts tokens
+func (sk *signingKey) verifies(now time.Time) bool {
+	switch sk.status {
+	case KeyActive:
+		return true
+	case KeyVerifyOnly:
+		return sk.graceUntil.IsZero() || now.Before(sk.graceUntil)
+	default:
+		return false
+	}
+}
+
+// legacySecret returns the single shared secret used when no signing keys are configured
+func legacySecret(cfg config.AuthConfig) ([]byte, error) {
+	switch {
+	case cfg.JWTSecret != "" && cfg.JWTSecretFile != "":
+		return nil, errors.New("set only one of JWT_SECRET_KEY or jwt_secret_file")
+	case cfg.JWTSecretFile != "":
+		secret, err := readSecret(config.SigningKeyConfig{SecretFile: cfg.JWTSecretFile})
+		if err != nil {
+			return nil, fmt.Errorf("jwt_secret_file: %w", err)
+		}
+		return secret, nil
+	case cfg.JWTSecret != "":
+		return []byte(cfg.JWTSecret), nil
+	default:
+		return nil, errors.New("no signing keys configured and neither JWT_SECRET_KEY nor jwt_secret_file is set")
+	}
+}
+
+// hasSecret reports whether any key of the ring uses the given secret
+func (kr *KeyRing) hasSecret(secret string) bool {
+	for _, key := range kr.keys {
+		if string(key.secret) == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// readSecret reads a key's secret from its file or environment variable
+func readSecret(keyConfig config.SigningKeyConfig) ([]byte, error) {
+	var secret string
+	switch {
+	case keyConfig.SecretFile != "" && keyConfig.SecretEnv != "":
+		return nil, errors.New("set only one of secret_file or secret_env")
+	case keyConfig.SecretFile != "":
+		data, err := os.ReadFile(keyConfig.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret: %w", err)
+		}
+		secret = strings.TrimSpace(string(data))
+	case keyConfig.SecretEnv != "":
+		secret = os.Getenv(keyConfig.SecretEnv)
+	default:
+		return nil, errors.New("needs secret_file or secret_env")
+	}
+	if secret == "" {
+		return nil, errors.New("secret is empty")
+	}
+	return []byte(secret), nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestNewKeyRing(t *testing.T) {
+	t.Setenv("KEY_ONE", "secret-one")
+	t.Setenv("KEY_TWO", "secret-two")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("secret-from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key := func(id, status, env string) config.SigningKeyConfig {
+		return config.SigningKeyConfig{ID: id, Status: status, SecretEnv: env}
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.AuthConfig
+		wantKeys map[string]string // nil when the ring is refused
+	}{
+		{"legacy secret", config.AuthConfig{JWTSecret: "legacy"}, map[string]string{"jwt_secret": KeyActive}},
+		{"legacy secret file", config.AuthConfig{JWTSecretFile: secretFile}, map[string]string{"jwt_secret": KeyActive}},
+		{"legacy secret from both sources", config.AuthConfig{JWTSecret: "legacy", JWTSecretFile: secretFile}, nil},
+		{"secret written in the config file", config.AuthConfig{JWTSecret: "legacy", JWTSecretFromYAML: true}, nil},
+		{"no secret", config.AuthConfig{}, nil},
+		{
+			"rotation",
+			config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("k2", KeyActive, "KEY_TWO"), key("k1", KeyVerifyOnly, "KEY_ONE"), {ID: "k0", Status: KeyRetired}}},
+			map[string]string{"k2": KeyActive, "k1": KeyVerifyOnly, "k0": KeyRetired},
+		},
+		{"two active keys", config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("k1", KeyActive, "KEY_ONE"), key("k2", KeyActive, "KEY_TWO")}}, nil},
+		{"no active key", config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("k1", KeyVerifyOnly, "KEY_ONE")}}, nil},
+		{"duplicate id", config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("k1", KeyActive, "KEY_ONE"), key("k1", KeyVerifyOnly, "KEY_TWO")}}, nil},
+		{"missing id", config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("", KeyActive, "KEY_ONE")}}, nil},
+		{"unknown status", config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("k1", "paused", "KEY_ONE")}}, nil},
+		{"empty secret", config.AuthConfig{SigningKeys: []config.SigningKeyConfig{key("k1", KeyActive, "KEY_UNSET")}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRing, err := NewKeyRing(tt.cfg)
+			if tt.wantKeys == nil {
+				if err == nil {
+					t.Fatalf("NewKeyRing() = %v, want an error", keyRing.Describe())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewKeyRing() error = %v", err)
+			}
+			if got := keyRing.Describe(); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("Describe() = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	t.Setenv("KEY_ACTIVE", "secret-active")
+	t.Setenv("KEY_GRACE", "secret-in-grace")
+	t.Setenv("KEY_EXPIRED", "secret-grace-ended")
+	keyRing, err := NewKeyRing(config.AuthConfig{SigningKeys: []config.SigningKeyConfig{
+		{ID: "k3", Status: KeyActive, SecretEnv: "KEY_ACTIVE"},
+		{ID: "k2", Status: KeyVerifyOnly, SecretEnv: "KEY_GRACE", GraceUntil: time.Now().Add(time.Hour)},
+		{ID: "k1", Status: KeyVerifyOnly, SecretEnv: "KEY_EXPIRED", GraceUntil: time.Now().Add(-time.Hour)},
+		{ID: "k0", Status: KeyRetired},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authService := NewAuthService(keyRing, time.Hour, nil, config.ClaimsPolicyConfig{}, logger.NewLogger("error", "json", "test"))
+
+	sign := func(secret, kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "u1", "exp": time.Now().Add(time.Hour).Unix()})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		wantOK bool
+	}{
+		{"active key by kid", sign("secret-active", "k3"), true},
+		{"active key without kid", sign("secret-active", ""), true},
+		{"key in grace by kid", sign("secret-in-grace", "k2"), true},
+		{"key in grace without kid", sign("secret-in-grace", ""), true},
+		{"grace ended by kid", sign("secret-grace-ended", "k1"), false},
+		{"grace ended without kid", sign("secret-grace-ended", ""), false},
+		{"retired key", sign("anything", "k0"), false},
+		{"unknown kid", sign("secret-active", "k9"), false},
+		{"kid naming another key", sign("secret-in-grace", "k3"), false},
+		{"unknown secret without kid", sign("not-a-ring-secret", ""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authService.ValidateJWT(context.Background(), tt.token)
+			if (err == nil) != tt.wantOK {
+				t.Errorf("ValidateJWT() error = %v, want ok %v", err, tt.wantOK)
+			}
+		})
+	}
+
+	// New tokens name the active key
+	issued, err := authService.GenerateJWT(context.Background(), &ports.UserInfo{ID: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(issued, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := parsed.Header["kid"]; kid != "k3" {
+		t.Errorf("issued token kid = %v, want k3", kid)
+	}
+	if _, err := authService.ValidateJWT(context.Background(), issued); err != nil {
+		t.Errorf("ValidateJWT(issued) error = %v", err)
+	}
+}
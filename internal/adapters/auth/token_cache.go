@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+const (
+	defaultTokenCacheTTL        = 5 * time.Minute
+	defaultTokenCacheMaxEntries = 10000
+)
+
+// TokenCache keeps successful token validations for a while so the auth service
+// is not asked about the same token on every request. Entries are keyed by a hash
+// of the token and never outlive the token's own expiry. Concurrent validations
+// of the same token share a single call.
+type TokenCache struct {
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cachedToken
+	inflight   map[string]*tokenValidation
+	mutex      sync.Mutex
+	metrics    ports.MetricsCollector
+}
+
+// cachedToken is a validated user and when the validation stops counting
+type cachedToken struct {
+	user      *UserInfo
+	expiresAt time.Time
+}
+
+// tokenValidation is a validation in progress that later callers wait for
+type tokenValidation struct {
+	done   chan struct{}
+	user   *UserInfo
+	err    error
+	purged bool // the token was purged while the validation ran; guarded by the cache mutex
+}
+
+// errTokenValidationFailed is handed to waiters when the shared validation did not return
+var errTokenValidationFailed = errors.New("token validation failed")
+
+// NewTokenCache creates a token cache; entries live at most ttl
+func NewTokenCache(ttl time.Duration, maxEntries int, metrics ports.MetricsCollector) *TokenCache {
+	if ttl <= 0 {
+		ttl = defaultTokenCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultTokenCacheMaxEntries
+	}
+	return &TokenCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cachedToken),
+		inflight:   make(map[string]*tokenValidation),
+		metrics:    metrics,
+	}
+}
+
+// Validate returns the cached user for the token, or runs validate once for all
+// concurrent callers and caches a successful result. Failures are not cached.
+func (tc *TokenCache) Validate(ctx context.Context, token string, validate func(context.Context) (*UserInfo, error)) (*UserInfo, error) {
+	key := tokenKey(token)
+	now := time.Now()
+
+	tc.mutex.Lock()
+	if entry, exists := tc.entries[key]; exists {
+		if now.Before(entry.expiresAt) {
+			tc.mutex.Unlock()
+			tc.metrics.IncrementCounter("gateway_token_cache_total", map[string]string{"result": "hit"})
+			return entry.user, nil
+		}
+		delete(tc.entries, key)
+	}
+	if call, exists := tc.inflight[key]; exists {
+		tc.mutex.Unlock()
+		tc.metrics.IncrementCounter("gateway_token_cache_total", map[string]string{"result": "shared"})
+		select {
+		case <-call.done:
+			return call.user, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &tokenValidation{done: make(chan struct{})}
+	tc.inflight[key] = call
+	tc.mutex.Unlock()
+	tc.metrics.IncrementCounter("gateway_token_cache_total", map[string]string{"result": "miss"})
+
+	// Waiters are released even when validate panics, so they never hang on the call
+	completed := false
+	defer func() {
+		if !completed {
+			call.err = errTokenValidationFailed
+		}
+		tc.finish(token, key, call)
+	}()
+
+	// The caller leaving early must not fail the callers waiting on this validation
+	call.user, call.err = validate(context.WithoutCancel(ctx))
+	completed = true
+	return call.user, call.err
+}
+
+// finish ends an in-flight validation, caching a successful result unless the
+// token was purged while it ran
+func (tc *TokenCache) finish(token, key string, call *tokenValidation) {
+	tc.mutex.Lock()
+	delete(tc.inflight, key)
+	if call.err == nil && !call.purged {
+		if expiresAt, cacheable := tc.expiry(token, time.Now()); cacheable {
+			if _, exists := tc.entries[key]; !exists && len(tc.entries) >= tc.maxEntries {
+				tc.evict(time.Now())
+			}
+			tc.entries[key] = cachedToken{user: call.user, expiresAt: expiresAt}
+		}
+	}
+	entries := len(tc.entries)
+	tc.mutex.Unlock()
+	close(call.done)
+
+	tc.metrics.SetGauge("gateway_token_cache_entries", float64(entries), nil)
+}
+
+// Purge forgets a token, for example once its user has logged out. A validation
+// of the token still in flight is not cached when it completes.
+func (tc *TokenCache) Purge(token string) {
+	key := tokenKey(token)
+	tc.mutex.Lock()
+	delete(tc.entries, key)
+	if call, exists := tc.inflight[key]; exists {
+		call.purged = true
+	}
+	entries := len(tc.entries)
+	tc.mutex.Unlock()
+	tc.metrics.SetGauge("gateway_token_cache_entries", float64(entries), nil)
+}
+
+// expiry returns when a cached validation of the token stops counting: after the
+// configured TTL, or earlier when the token itself expires
+func (tc *TokenCache) expiry(token string, now time.Time) (time.Time, bool) {
+	expiresAt := now.Add(tc.ttl)
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return expiresAt, true
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		tokenExpiry := time.Unix(int64(exp), 0)
+		if tokenExpiry.Before(expiresAt) {
+			expiresAt = tokenExpiry
+		}
+	}
+	return expiresAt, expiresAt.After(now)
+}
+
+// evict drops expired entries, or an arbitrary one when none have expired; callers hold the mutex
+func (tc *TokenCache) evict(now time.Time) {
+	for key, entry := range tc.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(tc.entries, key)
+		}
+	}
+	if len(tc.entries) < tc.maxEntries {
+		return
+	}
+	for key := range tc.entries {
+		delete(tc.entries, key)
+		return
+	}
+}
+
+// tokenKey hashes a token so raw tokens are not kept in memory as map keys
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+)
+
+func TestTokenCacheExpiry(t *testing.T) {
+	now := time.Now()
+	cache := NewTokenCache(5*time.Minute, 0, metrics.NewCollector())
+
+	tests := []struct {
+		name          string
+		token         string
+		wantExpiresAt time.Time
+		wantCacheable bool
+	}{
+		{"opaque token uses the ttl", "not-a-jwt", now.Add(5 * time.Minute), true},
+		{"token without exp uses the ttl", testToken(t, jwt.MapClaims{"sub": "u1"}), now.Add(5 * time.Minute), true},
+		{"token expiring after the ttl", testToken(t, jwt.MapClaims{"exp": now.Add(time.Hour).Unix()}), now.Add(5 * time.Minute), true},
+		{"token expiring before the ttl", testToken(t, jwt.MapClaims{"exp": now.Add(time.Minute).Unix()}), time.Unix(now.Add(time.Minute).Unix(), 0), true},
+		{"expired token", testToken(t, jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}), time.Unix(now.Add(-time.Minute).Unix(), 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt, cacheable := cache.expiry(tt.token, now)
+			if !expiresAt.Equal(tt.wantExpiresAt) || cacheable != tt.wantCacheable {
+				t.Errorf("expiry() = %v, %v, want %v, %v", expiresAt, cacheable, tt.wantExpiresAt, tt.wantCacheable)
+			}
+		})
+	}
+}
+
+func TestTokenCacheCachesSuccessOnly(t *testing.T) {
+	cache := NewTokenCache(time.Minute, 0, metrics.NewCollector())
+	calls := 0
+	fail := func(context.Context) (*UserInfo, error) {
+		calls++
+		return nil, errors.New("invalid token")
+	}
+	succeed := func(context.Context) (*UserInfo, error) {
+		calls++
+		return &UserInfo{ID: "u1"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Validate(context.Background(), "token", fail); err == nil {
+			t.Fatal("failed validation returned no error")
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if user, err := cache.Validate(context.Background(), "token", succeed); err != nil || user.ID != "u1" {
+			t.Fatalf("Validate() = %+v, %v", user, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("validate ran %d times, want 3", calls)
+	}
+}
+
+func TestTokenCachePurgeDuringValidation(t *testing.T) {
+	cache := NewTokenCache(time.Minute, 0, metrics.NewCollector())
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Validate(context.Background(), "token", func(context.Context) (*UserInfo, error) {
+			close(started)
+			<-finish
+			return &UserInfo{ID: "u1"}, nil
+		})
+	}()
+	<-started
+	cache.Purge("token")
+	close(finish)
+	<-done
+
+	calls := 0
+	cache.Validate(context.Background(), "token", func(context.Context) (*UserInfo, error) {
+		calls++
+		return &UserInfo{ID: "u1"}, nil
+	})
+	if calls != 1 {
+		t.Error("a validation purged while in flight was cached")
+	}
+}
+
+func TestTokenCacheReleasesWaitersWhenValidationPanics(t *testing.T) {
+	cache := NewTokenCache(time.Minute, 0, metrics.NewCollector())
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	go func() {
+		defer func() { recover() }()
+		cache.Validate(context.Background(), "token", func(context.Context) (*UserInfo, error) {
+			close(started)
+			<-finish
+			panic("validator bug")
+		})
+	}()
+	<-started
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := cache.Validate(context.Background(), "token", func(context.Context) (*UserInfo, error) {
+			return &UserInfo{ID: "u1"}, nil
+		})
+		result <- err
+	}()
+
+	// Let the second caller join the in-flight validation before it fails
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline) && !sharedValidation(cache); {
+		time.Sleep(time.Millisecond)
+	}
+	close(finish)
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, errTokenValidationFailed) {
+			t.Errorf("waiter error = %v, want %v", err, errTokenValidationFailed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released after the validation panicked")
+	}
+}
+
+// sharedValidation reports whether a caller has joined an in-flight validation
+func sharedValidation(cache *TokenCache) bool {
+	for _, counter := range cache.metrics.(*metrics.Collector).Snapshot().Counters {
+		if counter.Name == "gateway_token_cache_total" && counter.Labels["result"] == "shared" {
+			return true
+		}
+	}
+	return false
+}
+
+// testToken builds an HS256 token with the given claims; the cache never checks signatures
+func testToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,139 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// dnsLookupTimeout bounds a single resolution attempt
+const dnsLookupTimeout = 5 * time.Second
+
+// dnsSource resolves A/AAAA or SRV records and refreshes them periodically.
+// The standard resolver does not expose record TTLs, so the configured refresh
+// interval acts as the TTL. The last successful answer is kept when a lookup fails.
+type dnsSource struct {
+	service  string
+	kind     string
+	name     string
+	scheme   string
+	port     int
+	refresh  time.Duration
+	resolver *net.Resolver
+	urls     []string
+	mutex    sync.RWMutex
+	done     chan struct{}
+	once     sync.Once
+	logger   ports.Logger
+}
+
+// newDNSSource creates a DNS source and starts refreshing it in the background
+func newDNSSource(service, kind, name, scheme string, port int, refresh time.Duration, logger ports.Logger) *dnsSource {
+	s := &dnsSource{
+		service:  service,
+		kind:     kind,
+		name:     name,
+		scheme:   scheme,
+		port:     port,
+		refresh:  refresh,
+		resolver: net.DefaultResolver,
+		done:     make(chan struct{}),
+		logger:   logger,
+	}
+	s.resolve()
+	go s.run()
+	return s
+}
+
+// endpoints returns the last resolved endpoints
+func (s *dnsSource) endpoints() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.urls
+}
+
+// stop stops the background refresh
+func (s *dnsSource) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// run re-resolves the records every refresh interval
+func (s *dnsSource) run() {
+	ticker := time.NewTicker(s.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.resolve()
+		}
+	}
+}
+
+// resolve looks the records up and stores the resulting endpoints
+func (s *dnsSource) resolve() {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+	defer cancel()
+
+	urls, err := s.lookup(ctx)
+	if err != nil || len(urls) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no records found")
+		}
+		s.logger.Warn("Service discovery lookup failed", map[string]interface{}{
+			"service": s.service,
+			"type":    s.kind,
+			"name":    s.name,
+			"error":   err.Error(),
+		})
+		return
+	}
+	sort.Strings(urls)
+
+	s.mutex.Lock()
+	changed := strings.Join(urls, ",") != strings.Join(s.urls, ",")
+	s.urls = urls
+	s.mutex.Unlock()
+
+	if changed {
+		s.logger.Info("🔎 Service endpoints updated", map[string]interface{}{
+			"service":   s.service,
+			"type":      s.kind,
+			"endpoints": urls,
+		})
+	}
+}
+
+// lookup resolves the configured name into endpoint URLs
+func (s *dnsSource) lookup(ctx context.Context) ([]string, error) {
+	var urls []string
+
+	if s.kind == TypeDNSSRV {
+		_, records, err := s.resolver.LookupSRV(ctx, "", "", s.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			urls = append(urls, s.scheme+"://"+net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+		return urls, nil
+	}
+
+	addrs, err := s.resolver.LookupHost(ctx, s.name)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		urls = append(urls, s.scheme+"://"+net.JoinHostPort(addr, strconv.Itoa(s.port)))
+	}
+	return urls, nil
+}
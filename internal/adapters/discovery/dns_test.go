@@ -0,0 +1,190 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+)
+
+// Record types answered by the fake DNS server
+const (
+	typeA   = 1
+	typeSRV = 33
+)
+
+// fakeDNS answers queries from a fixed table of records keyed by type and name
+type fakeDNS struct {
+	records map[uint16]map[string][][]byte // record type -> name -> rdata
+	mutex   sync.Mutex
+	down    bool
+}
+
+// resolver returns a resolver whose every query is answered by the fake server
+func (f *fakeDNS) resolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			f.mutex.Lock()
+			down := f.down
+			f.mutex.Unlock()
+			if down {
+				return nil, errors.New("dns server unreachable")
+			}
+			client, server := net.Pipe()
+			go f.serve(server)
+			return client, nil
+		},
+	}
+}
+
+// setDown makes every later query fail
+func (f *fakeDNS) setDown(down bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.down = down
+}
+
+// serve answers length-prefixed queries on a stream connection until it closes
+func (f *fakeDNS) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+		response := f.answer(query)
+		framed := binary.BigEndian.AppendUint16(nil, uint16(len(response)))
+		if _, err := conn.Write(append(framed, response...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer builds the response to a single-question query
+func (f *fakeDNS) answer(query []byte) []byte {
+	// The question runs from the end of the header to the end of its class
+	end := 12
+	var labels []string
+	for query[end] != 0 {
+		size := int(query[end])
+		labels = append(labels, string(query[end+1:end+1+size]))
+		end += 1 + size
+	}
+	qtype := binary.BigEndian.Uint16(query[end+1:])
+	end += 5
+	name := strings.ToLower(strings.Join(labels, "."))
+
+	rdatas := f.records[qtype][name]
+	response := append([]byte{}, query[0:2]...) // same ID
+	response = append(response, 0x81, 0x80)     // response, recursion available, no error
+	response = binary.BigEndian.AppendUint16(response, 1)
+	response = binary.BigEndian.AppendUint16(response, uint16(len(rdatas)))
+	response = append(response, 0, 0, 0, 0)
+	response = append(response, query[12:end]...)
+	for _, rdata := range rdatas {
+		response = append(response, 0xc0, 12) // name points at the question
+		response = binary.BigEndian.AppendUint16(response, qtype)
+		response = binary.BigEndian.AppendUint16(response, 1) // class IN
+		response = binary.BigEndian.AppendUint32(response, 60)
+		response = binary.BigEndian.AppendUint16(response, uint16(len(rdata)))
+		response = append(response, rdata...)
+	}
+	return response
+}
+
+// srvRecord encodes the rdata of an SRV record
+func srvRecord(port uint16, target string) []byte {
+	rdata := []byte{0, 10, 0, 5} // priority, weight
+	rdata = binary.BigEndian.AppendUint16(rdata, port)
+	for _, label := range strings.Split(strings.TrimSuffix(target, "."), ".") {
+		rdata = append(rdata, byte(len(label)))
+		rdata = append(rdata, label...)
+	}
+	return append(rdata, 0)
+}
+
+func newTestDNSSource(kind, name string, dns *fakeDNS) *dnsSource {
+	return &dnsSource{
+		service:  "plants",
+		kind:     kind,
+		name:     name,
+		scheme:   "http",
+		port:     8080,
+		resolver: dns.resolver(),
+		done:     make(chan struct{}),
+		logger:   logger.NewLogger("error", "json", "test"),
+	}
+}
+
+func TestDNSSourceResolvesRecords(t *testing.T) {
+	dns := &fakeDNS{records: map[uint16]map[string][][]byte{
+		typeA: {"plants.svc.test": {{10, 0, 0, 7}, {10, 0, 0, 5}}},
+		typeSRV: {"_http._tcp.plants.svc.test": {
+			srvRecord(9001, "plants-1.svc.test."),
+			srvRecord(9000, "plants-0.svc.test."),
+		}},
+	}}
+
+	tests := []struct {
+		name string
+		kind string
+		host string
+		want []string
+	}{
+		{"A records use the configured port", TypeDNSA, "plants.svc.test.", []string{"http://10.0.0.5:8080", "http://10.0.0.7:8080"}},
+		{"SRV records carry their own ports", TypeDNSSRV, "_http._tcp.plants.svc.test.", []string{"http://plants-0.svc.test:9000", "http://plants-1.svc.test:9001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newTestDNSSource(tt.kind, tt.host, dns)
+			source.resolve()
+			if got := source.endpoints(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("endpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSSourceKeepsLastAnswer(t *testing.T) {
+	dns := &fakeDNS{records: map[uint16]map[string][][]byte{
+		typeA: {"plants.svc.test": {{10, 0, 0, 5}}},
+	}}
+	source := newTestDNSSource(TypeDNSA, "plants.svc.test.", dns)
+	source.resolve()
+	want := []string{"http://10.0.0.5:8080"}
+
+	// A failed lookup keeps the previous endpoints
+	dns.setDown(true)
+	source.resolve()
+	if got := source.endpoints(); !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints() after a failed lookup = %v, want %v", got, want)
+	}
+
+	// So does an empty answer, which would otherwise leave the service unroutable
+	dns.setDown(false)
+	dns.records[typeA]["plants.svc.test"] = nil
+	source.resolve()
+	if got := source.endpoints(); !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints() after an empty answer = %v, want %v", got, want)
+	}
+
+	// A new answer replaces them
+	dns.records[typeA]["plants.svc.test"] = [][]byte{{10, 0, 0, 9}}
+	source.resolve()
+	if got := source.endpoints(); !reflect.DeepEqual(got, []string{"http://10.0.0.9:8080"}) {
+		t.Errorf("endpoints() after a change = %v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// fileWatcher polls a JSON or YAML file mapping service names to endpoint URLs:
+//
+//	analytics:
+//	  - "http://10.0.0.5:8000"
+//	  - "http://10.0.0.6:8000"
+//
+// A file that fails to parse is ignored and the previous endpoints are kept.
+type fileWatcher struct {
+	path      string
+	refresh   time.Duration
+	services  map[string][]string
+	modTime   time.Time
+	mutex     sync.RWMutex
+	done      chan struct{}
+	closeOnce sync.Once
+	logger    ports.Logger
+}
+
+// newFileWatcher reads the endpoints file and starts watching it for changes
+func newFileWatcher(path string, refresh time.Duration, logger ports.Logger) (*fileWatcher, error) {
+	w := &fileWatcher{
+		path:    path,
+		refresh: refresh,
+		done:    make(chan struct{}),
+		logger:  logger,
+	}
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// endpoints returns the endpoints listed for a service
+func (w *fileWatcher) endpoints(service string) []string {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.services[service]
+}
+
+// stop stops watching the file
+func (w *fileWatcher) stop() {
+	w.closeOnce.Do(func() { close(w.done) })
+}
+
+// run reloads the file whenever its modification time changes
+func (w *fileWatcher) run() {
+	ticker := time.NewTicker(w.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(w.path)
+			if err != nil {
+				w.logger.Warn("Service endpoints file unavailable", map[string]interface{}{
+					"file":  w.path,
+					"error": err.Error(),
+				})
+				continue
+			}
+
+			w.mutex.RLock()
+			unchanged := info.ModTime().Equal(w.modTime)
+			w.mutex.RUnlock()
+			if unchanged {
+				continue
+			}
+
+			if err := w.load(); err != nil {
+				w.logger.Warn("Service endpoints file rejected", map[string]interface{}{
+					"file":  w.path,
+					"error": err.Error(),
+				})
+				continue
+			}
+			w.logger.Info("🔎 Service endpoints file reloaded", map[string]interface{}{
+				"file": w.path,
+			})
+		}
+	}
+}
+
+// load parses the file and replaces the known endpoints
+func (w *fileWatcher) load() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat endpoints file: %w", err)
+	}
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to read endpoints file: %w", err)
+	}
+
+	// YAML is a superset of JSON, so both formats are parsed the same way
+	services := make(map[string][]string)
+	if err := yaml.Unmarshal(data, &services); err != nil {
+		return fmt.Errorf("failed to parse endpoints file: %w", err)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.services = services
+	w.modTime = info.ModTime()
+	return nil
+}
+
+// fileSource exposes the endpoints of one service from a shared file watcher
+type fileSource struct {
+	service string
+	watcher *fileWatcher
+}
+
+// endpoints returns the endpoints listed for the service
+func (s *fileSource) endpoints() []string {
+	return s.watcher.endpoints(s.service)
+}
+
+// stop is handled by the registry, which owns the shared watcher
+func (s *fileSource) stop() {}
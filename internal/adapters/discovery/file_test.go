@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+)
+
+// writeEndpoints replaces the endpoints file and moves its modification time
+// forward so the watcher sees the change
+func writeEndpoints(t *testing.T, path, content string, generation int) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Duration(generation) * time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForEndpoints polls until a service reports the wanted endpoints
+func waitForEndpoints(t *testing.T, registry *Registry, service string, want []string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := registry.Endpoints(service)
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("endpoints(%s) = %v, want %v", service, got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFileDiscovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeEndpoints(t, path, `
+analytics:
+  - "http://10.0.0.5:8000"
+  - "http://10.0.0.6:8000"
+reports: ["http://10.0.1.5:8000"]
+`, 0)
+
+	fileDiscovery := &config.DiscoveryConfig{Type: TypeFile, File: path, Refresh: 10 * time.Millisecond}
+	registry := NewRegistry(logger.NewLogger("error", "json", "test"))
+	defer registry.Stop()
+	err := registry.Update(map[string]config.ServiceConfig{
+		"analytics": {URL: "http://analytics:8000", Discovery: fileDiscovery},
+		"reports":   {URL: "http://reports:8000", Discovery: fileDiscovery},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.files) != 1 {
+		t.Errorf("watchers = %d, want one shared by both services", len(registry.files))
+	}
+	waitForEndpoints(t, registry, "analytics", []string{"http://10.0.0.5:8000", "http://10.0.0.6:8000"})
+	waitForEndpoints(t, registry, "reports", []string{"http://10.0.1.5:8000"})
+
+	// JSON is accepted too, and changes are picked up without a reload
+	writeEndpoints(t, path, `{"analytics": ["http://10.0.0.7:8000"], "reports": ["http://10.0.1.5:8000"]}`, 1)
+	waitForEndpoints(t, registry, "analytics", []string{"http://10.0.0.7:8000"})
+
+	// A broken file keeps the previous endpoints
+	writeEndpoints(t, path, "analytics: [unclosed", 2)
+	time.Sleep(50 * time.Millisecond)
+	waitForEndpoints(t, registry, "analytics", []string{"http://10.0.0.7:8000"})
+}
@@ -0,0 +1,193 @@
+package discovery
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Discovery types accepted in the services configuration
+const (
+	TypeStatic = "static"
+	TypeDNSA   = "dns_a"
+	TypeDNSSRV = "dns_srv"
+	TypeFile   = "file"
+)
+
+// defaultRefresh is used when a dynamic source does not set its own interval
+const defaultRefresh = 30 * time.Second
+
+// source provides the current endpoints of one service
+type source interface {
+	endpoints() []string
+	stop()
+}
+
+// Registry implements ports.ServiceDiscovery with one source per service
+type Registry struct {
+	sources map[string]source
+	files   map[string]*fileWatcher
+	mutex   sync.RWMutex
+	logger  ports.Logger
+}
+
+// NewRegistry creates a new discovery registry
+func NewRegistry(logger ports.Logger) *Registry {
+	return &Registry{
+		sources: make(map[string]source),
+		files:   make(map[string]*fileWatcher),
+		logger:  logger,
+	}
+}
+
+// Update replaces the discovery sources for the given services. Nothing is
+// replaced if any service has an invalid discovery configuration.
+func (r *Registry) Update(services map[string]config.ServiceConfig) error {
+	sources := make(map[string]source, len(services))
+	files := make(map[string]*fileWatcher)
+
+	for name, service := range services {
+		src, err := r.newSource(name, service, files)
+		if err != nil {
+			for _, created := range sources {
+				created.stop()
+			}
+			for _, watcher := range files {
+				watcher.stop()
+			}
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+		sources[name] = src
+	}
+
+	r.mutex.Lock()
+	previousSources, previousFiles := r.sources, r.files
+	r.sources, r.files = sources, files
+	r.mutex.Unlock()
+
+	for _, src := range previousSources {
+		src.stop()
+	}
+	for _, watcher := range previousFiles {
+		watcher.stop()
+	}
+	return nil
+}
+
+// Endpoints returns the base URLs of the known instances of a service
+func (r *Registry) Endpoints(serviceName string) []string {
+	r.mutex.RLock()
+	src, exists := r.sources[serviceName]
+	r.mutex.RUnlock()
+	if !exists {
+		return nil
+	}
+	return src.endpoints()
+}
+
+// Stop stops all background refreshes
+func (r *Registry) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, src := range r.sources {
+		src.stop()
+	}
+	for _, watcher := range r.files {
+		watcher.stop()
+	}
+	r.sources = make(map[string]source)
+	r.files = make(map[string]*fileWatcher)
+}
+
+// newSource creates the discovery source configured for a service
+func (r *Registry) newSource(name string, service config.ServiceConfig, files map[string]*fileWatcher) (source, error) {
+	discovery := service.Discovery
+	if discovery == nil || discovery.Type == "" || discovery.Type == TypeStatic {
+		endpoints := []string{service.URL}
+		if discovery != nil && len(discovery.Endpoints) > 0 {
+			endpoints = discovery.Endpoints
+		}
+		return &staticSource{urls: endpoints}, nil
+	}
+
+	refresh := discovery.Refresh
+	if refresh <= 0 {
+		refresh = defaultRefresh
+	}
+
+	switch discovery.Type {
+	case TypeDNSA, TypeDNSSRV:
+		scheme, port, err := defaultsFromURL(service.URL)
+		if err != nil {
+			return nil, err
+		}
+		if discovery.Scheme != "" {
+			scheme = discovery.Scheme
+		}
+		if discovery.Port != 0 {
+			port = discovery.Port
+		}
+		if discovery.Name == "" {
+			return nil, fmt.Errorf("%s discovery requires a name", discovery.Type)
+		}
+		return newDNSSource(name, discovery.Type, discovery.Name, scheme, port, refresh, r.logger), nil
+
+	case TypeFile:
+		if discovery.File == "" {
+			return nil, fmt.Errorf("file discovery requires a file")
+		}
+		watcher, exists := files[discovery.File]
+		if !exists {
+			var err error
+			watcher, err = newFileWatcher(discovery.File, refresh, r.logger)
+			if err != nil {
+				return nil, err
+			}
+			files[discovery.File] = watcher
+		}
+		return &fileSource{service: name, watcher: watcher}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown discovery type %q", discovery.Type)
+	}
+}
+
+// defaultsFromURL extracts the scheme and port of a service URL
+func defaultsFromURL(rawURL string) (string, int, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid service URL %q: %w", rawURL, err)
+	}
+	scheme := parsed.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := 80
+	if scheme == "https" {
+		port = 443
+	}
+	if parsed.Port() != "" {
+		if port, err = strconv.Atoi(parsed.Port()); err != nil {
+			return "", 0, fmt.Errorf("invalid port in service URL %q: %w", rawURL, err)
+		}
+	}
+	return scheme, port, nil
+}
+
+// staticSource always returns the configured endpoints
+type staticSource struct {
+	urls []string
+}
+
+// endpoints returns the configured endpoints
+func (s *staticSource) endpoints() []string {
+	return s.urls
+}
+
+// stop is a no-op for static sources
+func (s *staticSource) stop() {}
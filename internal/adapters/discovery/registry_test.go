@@ -0,0 +1,83 @@
+package discovery
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+)
+
+func TestRegistryUpdate(t *testing.T) {
+	endpointsFile := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeEndpoints(t, endpointsFile, `plants: ["http://10.0.0.5:8080"]`, 0)
+
+	tests := []struct {
+		name      string
+		discovery *config.DiscoveryConfig
+		want      []string // nil when the update is refused
+	}{
+		{"no discovery uses the service URL", nil, []string{"http://plants:8080"}},
+		{"static endpoints", &config.DiscoveryConfig{Type: TypeStatic, Endpoints: []string{"http://10.0.0.1:8080"}}, []string{"http://10.0.0.1:8080"}},
+		{"file", &config.DiscoveryConfig{Type: TypeFile, File: endpointsFile}, []string{"http://10.0.0.5:8080"}},
+		{"file without a path", &config.DiscoveryConfig{Type: TypeFile}, nil},
+		{"missing file", &config.DiscoveryConfig{Type: TypeFile, File: filepath.Join(t.TempDir(), "missing.yaml")}, nil},
+		{"dns without a name", &config.DiscoveryConfig{Type: TypeDNSA}, nil},
+		{"unknown type", &config.DiscoveryConfig{Type: "consul"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(logger.NewLogger("error", "json", "test"))
+			defer registry.Stop()
+			previous := map[string]config.ServiceConfig{"plants": {URL: "http://previous:8080"}}
+			if err := registry.Update(previous); err != nil {
+				t.Fatal(err)
+			}
+
+			err := registry.Update(map[string]config.ServiceConfig{
+				"plants": {URL: "http://plants:8080", Discovery: tt.discovery},
+			})
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("Update() accepted the configuration")
+				}
+				// A refused update leaves the previous sources in place
+				tt.want = []string{"http://previous:8080"}
+			} else if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if got := registry.Endpoints("plants"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Endpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultsFromURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantScheme string
+		wantPort   int
+		wantErr    bool
+	}{
+		{"http://plants", "http", 80, false},
+		{"https://plants", "https", 443, false},
+		{"http://plants:8080/base", "http", 8080, false},
+		{"plants", "http", 80, false},
+		{"http://plants:port", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			scheme, port, err := defaultsFromURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultsFromURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (scheme != tt.wantScheme || port != tt.wantPort) {
+				t.Errorf("defaultsFromURL() = %s, %d, want %s, %d", scheme, port, tt.wantScheme, tt.wantPort)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
+)
+
+// minAdminKeyLength keeps the admin key out of reach of guessing
+const minAdminKeyLength = 16
+
+// AdminHandler exposes runtime management endpoints for the gateway. They are
+// guarded by a dedicated admin key, not by the API keys of ordinary clients.
+type AdminHandler struct {
+	gatewayService *services.GatewayService
+	configProvider ports.ConfigProvider
+	faultInjector  *services.FaultInjector
+	adminKey       string
+	keyHeader      string
+	logger         ports.Logger
+}
+
+// NewAdminHandler creates a new admin handler; adminKey comes from LoadAdminKey
+func NewAdminHandler(
+	gatewayService *services.GatewayService,
+	configProvider ports.ConfigProvider,
+	faultInjector *services.FaultInjector,
+	adminKey string,
+	keyHeader string,
+	logger ports.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		gatewayService: gatewayService,
+		configProvider: configProvider,
+		faultInjector:  faultInjector,
+		adminKey:       adminKey,
+		keyHeader:      keyHeader,
+		logger:         logger,
+	}
+}
+
+// LoadAdminKey reads the admin key from ADMIN_API_KEY or the configured key file.
+// An empty key without an error means the admin API is not configured.
+func LoadAdminKey(cfg config.AdminConfig) (string, error) {
+	var key string
+	switch {
+	case cfg.APIKey != "" && cfg.APIKeyFile != "":
+		return "", errors.New("set only one of ADMIN_API_KEY or admin.api_key_file")
+	case cfg.APIKeyFile != "":
+		data, err := os.ReadFile(cfg.APIKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("admin.api_key_file: %w", err)
+		}
+		if key = strings.TrimSpace(string(data)); key == "" {
+			return "", errors.New("admin.api_key_file is empty")
+		}
+	case cfg.APIKey != "":
+		key = cfg.APIKey
+	default:
+		return "", nil
+	}
+	if len(key) < minAdminKeyLength {
+		return "", fmt.Errorf("admin API key must be at least %d characters", minAdminKeyLength)
+	}
+	return key, nil
+}
+
+// TrafficWeightsRequest represents a request to update variant weights of a route
+type TrafficWeightsRequest struct {
+	Path    string         `json:"path" binding:"required"`
+	Method  string         `json:"method" binding:"required"`
+	Weights map[string]int `json:"weights" binding:"required"`
+}
+
+// FaultToggleRequest switches fault injection on or off as a whole, for a route
+// (path and method) or for a service
+type FaultToggleRequest struct {
+	Path    string `json:"path"`
+	Method  string `json:"method"`
+	Service string `json:"service"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+// RegisterRoutes registers the admin routes. Nothing is registered without an
+// admin key, so the API answers 404 rather than trusting other credentials.
+func (ah *AdminHandler) RegisterRoutes(router *gin.Engine) {
+	if ah.adminKey == "" {
+		ah.logger.Warn("Admin API disabled: set ADMIN_API_KEY or admin.api_key_file to enable it", nil)
+		return
+	}
+	admin := router.Group("/admin", ah.requireAdminKey())
+	admin.POST("/reload", ah.HandleReload)
+	admin.GET("/traffic", ah.HandleGetTrafficWeights)
+	admin.PUT("/traffic", ah.HandleSetTrafficWeights)
+	admin.GET("/faults", ah.HandleGetFaults)
+	admin.PUT("/faults", ah.HandleToggleFaults)
+}
+
+// requireAdminKey rejects admin requests without the admin key
+func (ah *AdminHandler) requireAdminKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(ah.keyHeader)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(ah.adminKey)) != 1 {
+			ah.logger.Warn("Admin request rejected", map[string]interface{}{
+				"path":      c.Request.URL.Path,
+				"method":    c.Request.Method,
+				"remote_ip": c.ClientIP(),
+			})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or missing admin key",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HandleReload reloads the configuration and drops runtime overrides
+func (ah *AdminHandler) HandleReload(c *gin.Context) {
+	if err := ah.configProvider.ReloadConfig(); err != nil {
+		ah.logger.Error("Configuration reload failed", err, nil)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Configuration reload failed",
+		})
+		return
+	}
+	ah.gatewayService.TrafficSplitter().ResetOverrides()
+	ah.faultInjector.ResetOverrides()
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "reloaded",
+	})
+}
+
+// HandleGetTrafficWeights returns the effective weights of a split route
+func (ah *AdminHandler) HandleGetTrafficWeights(c *gin.Context) {
+	path := c.Query("path")
+	method := c.DefaultQuery("method", http.MethodGet)
+
+	routeConfig, found := ah.configProvider.GetRouteConfig(path, method)
+	if !found || routeConfig.TrafficSplit == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Route with traffic split not found",
+		})
+		return
+	}
+
+	routeKey := services.RouteKey(routeConfig.Method, routeConfig.Path)
+	c.JSON(http.StatusOK, gin.H{
+		"route":   routeKey,
+		"weights": ah.gatewayService.TrafficSplitter().Weights(routeKey, routeConfig.TrafficSplit),
+	})
+}
+
+// HandleSetTrafficWeights overrides the weights of a split route until the next reload
+func (ah *AdminHandler) HandleSetTrafficWeights(c *gin.Context) {
+	var request TrafficWeightsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	routeConfig, found := ah.configProvider.GetRouteConfig(request.Path, request.Method)
+	if !found || routeConfig.TrafficSplit == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Route with traffic split not found",
+		})
+		return
+	}
+
+	routeKey := services.RouteKey(routeConfig.Method, routeConfig.Path)
+	splitter := ah.gatewayService.TrafficSplitter()
+	if err := splitter.SetWeights(routeKey, routeConfig.TrafficSplit, request.Weights); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"route":   routeKey,
+		"weights": splitter.Weights(routeKey, routeConfig.TrafficSplit),
+	})
+}
+
+// HandleGetFaults reports whether fault injection is on and which targets are switched off
+func (ah *AdminHandler) HandleGetFaults(c *gin.Context) {
+	enabled, disabled := ah.faultInjector.State()
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":          enabled,
+		"disabled_targets": disabled,
+	})
+}
+
+// HandleToggleFaults switches fault injection on or off. Route and service switches
+// last until the next reload.
+func (ah *AdminHandler) HandleToggleFaults(c *gin.Context) {
+	var request FaultToggleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	target := ""
+	switch {
+	case request.Path != "":
+		routeConfig, found := ah.configProvider.GetRouteConfig(request.Path, request.Method)
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Route not found",
+			})
+			return
+		}
+		target = services.FaultRouteTarget(services.RouteKey(routeConfig.Method, routeConfig.Path))
+	case request.Service != "":
+		if _, found := ah.configProvider.GetServiceConfig(request.Service); !found {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Service not found",
+			})
+			return
+		}
+		target = services.FaultServiceTarget(request.Service)
+	}
+
+	ah.faultInjector.SetEnabled(target, *request.Enabled)
+	enabled, disabled := ah.faultInjector.State()
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":          enabled,
+		"disabled_targets": disabled,
+	})
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
+)
+
+const testAdminKey = "admin-key-0123456789"
+
+func TestLoadAdminKey(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	keyFile := writeFile("key", testAdminKey+"\n")
+	emptyFile := writeFile("empty", "\n")
+
+	tests := []struct {
+		name    string
+		cfg     config.AdminConfig
+		want    string
+		wantErr bool
+	}{
+		{"not configured", config.AdminConfig{}, "", false},
+		{"from the environment", config.AdminConfig{APIKey: testAdminKey}, testAdminKey, false},
+		{"from a file", config.AdminConfig{APIKeyFile: keyFile}, testAdminKey, false},
+		{"both set", config.AdminConfig{APIKey: testAdminKey, APIKeyFile: keyFile}, "", true},
+		{"too short", config.AdminConfig{APIKey: "test-api-key"}, "", true},
+		{"empty file", config.AdminConfig{APIKeyFile: emptyFile}, "", true},
+		{"missing file", config.AdminConfig{APIKeyFile: filepath.Join(dir, "missing")}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadAdminKey(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadAdminKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LoadAdminKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		adminKey   string
+		header     string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"not served without an admin key", "", testAdminKey, "GET", "/admin/faults", "", http.StatusNotFound},
+		{"client API key cannot open it", "", "test-api-key", "PUT", "/admin/faults", `{"enabled": true}`, http.StatusNotFound},
+		{"missing key", testAdminKey, "", "GET", "/admin/faults", "", http.StatusUnauthorized},
+		{"client API key", testAdminKey, "test-api-key", "PUT", "/admin/faults", `{"enabled": true}`, http.StatusUnauthorized},
+		{"key prefix", testAdminKey, testAdminKey[:10], "GET", "/admin/faults", "", http.StatusUnauthorized},
+		{"admin key", testAdminKey, testAdminKey, "GET", "/admin/faults", "", http.StatusOK},
+		{"toggle faults", testAdminKey, testAdminKey, "PUT", "/admin/faults", `{"enabled": true}`, http.StatusOK},
+		{"unknown service", testAdminKey, testAdminKey, "PUT", "/admin/faults", `{"service": "nope", "enabled": true}`, http.StatusNotFound},
+		{"unknown route", testAdminKey, testAdminKey, "GET", "/admin/traffic?path=/nope", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.NewLogger("error", "json", "test")
+			injector := services.NewFaultInjector(false, metrics.NewCollector(), log)
+			handler := NewAdminHandler(nil, routeTable{}, injector, tt.adminKey, "X-Admin-Key", log)
+			router := gin.New()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-Admin-Key", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+		})
+	}
+
+	// The fault switch only moves for an authorised request
+	log := logger.NewLogger("error", "json", "test")
+	injector := services.NewFaultInjector(false, metrics.NewCollector(), log)
+	router := gin.New()
+	NewAdminHandler(nil, routeTable{}, injector, testAdminKey, "X-Admin-Key", log).RegisterRoutes(router)
+	req := httptest.NewRequest("PUT", "/admin/faults", strings.NewReader(`{"enabled": true}`))
+	req.Header.Set("X-Admin-Key", "test-api-key")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if enabled, _ := injector.State(); enabled {
+		t.Error("a client API key switched fault injection on")
+	}
+}
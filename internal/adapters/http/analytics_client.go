@@ -5,12 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
 
+	coreDomain "github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/domain"
 )
@@ -40,19 +40,29 @@ func parseFlexibleTimestamp(timestampStr string) time.Time {
 	return time.Now()
 }
 
+// defaultAnalyticsResponseLimit bounds analytics responses when no limit is given,
+// matching the gateway's default MAX_RESPONSE_SIZE
+const defaultAnalyticsResponseLimit = 50 << 20
+
 // AnalyticsHTTPClient implements the AnalyticsClient interface using HTTP calls
 type AnalyticsHTTPClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL         string
+	httpClient      *http.Client
+	maxResponseSize int64
 }
 
-// NewAnalyticsHTTPClient creates a new analytics HTTP client
-func NewAnalyticsHTTPClient(baseURL string) ports.AnalyticsClient {
+// NewAnalyticsHTTPClient creates a new analytics HTTP client. Response bodies
+// larger than maxResponseSize bytes are rejected; zero uses the default limit.
+func NewAnalyticsHTTPClient(baseURL string, maxResponseSize int64) ports.AnalyticsClient {
+	if maxResponseSize <= 0 {
+		maxResponseSize = defaultAnalyticsResponseLimit
+	}
 	return &AnalyticsHTTPClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxResponseSize: maxResponseSize,
 	}
 }
 
@@ -71,9 +81,9 @@ func (c *AnalyticsHTTPClient) GetSingleMetricReport(ctx context.Context, metricN
 	if filter.Limit != nil {
 		params.Add("limit", strconv.Itoa(*filter.Limit))
 	}
-	url := fmt.Sprintf("%s/api/v1/analytics/report/%s?%s", c.baseURL, metricName, params.Encode())
+	requestURL := fmt.Sprintf("%s/api/v1/analytics/report/%s?%s", c.baseURL, url.PathEscape(metricName), params.Encode())
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -90,7 +100,7 @@ func (c *AnalyticsHTTPClient) GetSingleMetricReport(ctx context.Context, metricN
 		return nil, fmt.Errorf("analytics service returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := coreDomain.ReadLimited(resp.Body, c.maxResponseSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -128,7 +138,7 @@ func (c *AnalyticsHTTPClient) GetMultiMetricReport(ctx context.Context, request
 		return nil, fmt.Errorf("analytics service returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := coreDomain.ReadLimited(resp.Body, c.maxResponseSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -154,8 +164,8 @@ func (c *AnalyticsHTTPClient) GetTrendAnalysis(ctx context.Context, request doma
 	}
 	params.Add("interval", request.Interval)
 
-	url := fmt.Sprintf("%s/api/v1/analytics/trends/%s?%s", c.baseURL, request.MetricName, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	requestURL := fmt.Sprintf("%s/api/v1/analytics/trends/%s?%s", c.baseURL, url.PathEscape(request.MetricName), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -172,7 +182,7 @@ func (c *AnalyticsHTTPClient) GetTrendAnalysis(ctx context.Context, request doma
 		return nil, fmt.Errorf("analytics service returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := coreDomain.ReadLimited(resp.Body, c.maxResponseSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -205,7 +215,7 @@ func (c *AnalyticsHTTPClient) GetSupportedMetrics(ctx context.Context) (*domain.
 		return nil, fmt.Errorf("analytics service returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := coreDomain.ReadLimited(resp.Body, c.maxResponseSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -253,7 +263,7 @@ func (c *AnalyticsHTTPClient) GetAnalyticsHealth(ctx context.Context) (*domain.H
 		return nil, fmt.Errorf("analytics service returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := coreDomain.ReadLimited(resp.Body, c.maxResponseSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
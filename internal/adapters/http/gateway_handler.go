@@ -2,14 +2,23 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/auth"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/discovery"
+	metricsAdapter "github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/upstream"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
@@ -18,9 +27,12 @@ import (
 
 // GatewayHandler handles HTTP requests for the API Gateway
 type GatewayHandler struct {
-	gatewayService *services.GatewayService
-	configProvider ports.ConfigProvider
-	logger         ports.Logger
+	gatewayService   *services.GatewayService
+	configProvider   ports.ConfigProvider
+	healthChecker    ports.HealthChecker
+	metricsCollector *metricsAdapter.Collector
+	logger           ports.Logger
+	draining         atomic.Bool
 }
 
 // context key type to avoid collisions when using context.WithValue
@@ -32,12 +44,16 @@ const requestIDKey ctxKey = "request_id"
 func NewGatewayHandler(
 	gatewayService *services.GatewayService,
 	configProvider ports.ConfigProvider,
+	healthChecker ports.HealthChecker,
+	metricsCollector *metricsAdapter.Collector,
 	logger ports.Logger,
 ) *GatewayHandler {
 	return &GatewayHandler{
-		gatewayService: gatewayService,
-		configProvider: configProvider,
-		logger:         logger,
+		gatewayService:   gatewayService,
+		configProvider:   configProvider,
+		healthChecker:    healthChecker,
+		metricsCollector: metricsCollector,
+		logger:           logger,
 	}
 }
 
@@ -53,6 +69,7 @@ func (gh *GatewayHandler) HandleRequest(c *gin.Context) {
 		Path:      c.Request.URL.Path,
 		Headers:   make(map[string]string),
 		Query:     make(map[string]string),
+		RawQuery:  c.Request.URL.RawQuery,
 		StartTime: startTime,
 	}
 
@@ -93,26 +110,42 @@ func (gh *GatewayHandler) HandleRequest(c *gin.Context) {
 				var body interface{}
 				if err := c.ShouldBindJSON(&body); err == nil {
 					reqCtx.Body = body
+				} else if IsBodyTooLarge(err) {
+					gh.rejectOversizedBody(c, requestID)
+					return
 				}
 			}
 		} else if strings.Contains(contentType, "multipart/form-data") {
-			// For multipart/form-data, store the raw body and mark it for special handling
+			// Stream multipart uploads straight to the upstream instead of buffering them;
+			// the body is already capped by the request limiter
 			if c.Request.Body != nil {
-				bodyBytes, err := io.ReadAll(c.Request.Body)
+				body, err := gh.uploadBody(c)
 				if err != nil {
-					gh.logger.Error("Failed to read multipart body", err, map[string]interface{}{
+					gh.logger.Warn("Rejected multipart upload", map[string]interface{}{
 						"request_id": requestID,
+						"error":      err.Error(),
 					})
-				} else {
-					reqCtx.Body = bodyBytes
-					reqCtx.Headers["x-multipart-body"] = "true"
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":      "Invalid multipart request",
+						"code":       "MALFORMED_MULTIPART",
+						"request_id": requestID,
+					})
+					return
 				}
+				// Requests rejected before reaching the upstream never close the body,
+				// which would leave the upload validator's parser waiting forever
+				defer body.Close()
+				reqCtx.Body = body
+				reqCtx.ContentLength = c.Request.ContentLength
 			}
 		} else {
 			// For other content types, try to read the body as raw bytes if it's not JSON
 			if c.Request.Body != nil && c.Request.ContentLength > 0 {
 				bodyBytes, err := io.ReadAll(c.Request.Body)
-				if err != nil {
+				if IsBodyTooLarge(err) {
+					gh.rejectOversizedBody(c, requestID)
+					return
+				} else if err != nil {
 					gh.logger.Error("Failed to read raw body", err, map[string]interface{}{
 						"request_id": requestID,
 					})
@@ -200,31 +233,158 @@ func (gh *GatewayHandler) HandleRequest(c *gin.Context) {
 	c.JSON(response.StatusCode, response.Body)
 }
 
-// HandleHealth handles health check requests
+// uploadBody returns the multipart request body, wrapped with streaming validation
+// when the route declares an upload policy
+func (gh *GatewayHandler) uploadBody(c *gin.Context) (io.ReadCloser, error) {
+	routeConfig, found := gh.configProvider.GetRouteConfig(c.Request.URL.Path, c.Request.Method)
+	if !found || routeConfig.Upload == nil {
+		return c.Request.Body, nil
+	}
+
+	_, params, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("multipart boundary missing")
+	}
+
+	return newMultipartValidator(c.Request.Body, boundary, *routeConfig.Upload), nil
+}
+
+// rejectOversizedBody responds with 413 when the body exceeded the limit while being read
+func (gh *GatewayHandler) rejectOversizedBody(c *gin.Context, requestID string) {
+	gh.logger.Warn("Request body exceeded size limit", map[string]interface{}{
+		"request_id": requestID,
+		"path":       c.Request.URL.Path,
+		"method":     c.Request.Method,
+	})
+	c.Header("Connection", "close")
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":      "Request body too large",
+		"code":       "BODY_TOO_LARGE",
+		"request_id": requestID,
+	})
+}
+
+// HandleHealth handles health check requests. Services report the result of
+// their latest background health checks; the gateway is degraded while any
+// checked service is unhealthy.
 func (gh *GatewayHandler) HandleHealth(c *gin.Context) {
-	health := gin.H{
-		"status":    "healthy",
+	statuses, err := gh.healthChecker.CheckAllServices(c.Request.Context())
+	if err != nil {
+		gh.logger.Error("Failed to read service health", err, nil)
+	}
+
+	overall := "healthy"
+	services := make(map[string]interface{})
+	for _, name := range gh.configProvider.ListServices() {
+		serviceConfig, _ := gh.configProvider.GetServiceConfig(name)
+		status, checked := statuses[name]
+		if !checked {
+			status, _ = gh.healthChecker.CheckHealth(c.Request.Context(), name)
+		}
+		if status.Status == string(domain.ServiceStatusUnhealthy) {
+			overall = "degraded"
+		}
+
+		service := gin.H{
+			"url":    serviceConfig.URL,
+			"status": status.Status,
+		}
+		if status.Message != "" {
+			service["message"] = status.Message
+		}
+		if status.Timestamp != "" {
+			service["last_checked"] = status.Timestamp
+		}
+		if instances, found := status.Metadata["instances"]; found {
+			service["instances"] = instances
+		}
+		services[name] = service
+	}
+
+	if gh.draining.Load() {
+		overall = "draining"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    overall,
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"version":   "1.0.0",
-		"services":  make(map[string]interface{}),
+		"services":  services,
+	})
+}
+
+// HandleLivez reports that the process is up; it does not look at upstream services
+func (gh *GatewayHandler) HandleLivez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "alive",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// HandleReadyz reports whether the gateway should receive traffic. It fails while
+// the gateway is draining or any critical service is unhealthy. Critical services
+// without a health check are assumed ready.
+func (gh *GatewayHandler) HandleReadyz(c *gin.Context) {
+	if gh.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "draining",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
 	}
 
-	// Add basic service status (could be enhanced with actual health checks)
-	services := []string{"analytics", "auth", "data_management", "plant_management"}
-	for _, service := range services {
-		if serviceInfo, exists := gh.configProvider.GetServiceConfig(service); exists {
-			health["services"].(map[string]interface{})[service] = gin.H{
-				"url":    serviceInfo.URL,
-				"status": "unknown", // Could implement actual health checking
-			}
+	unhealthy := make(map[string]interface{})
+	for _, name := range gh.configProvider.ListServices() {
+		if serviceConfig, found := gh.configProvider.GetServiceConfig(name); !found || !serviceConfig.Critical {
+			continue
 		}
+		status, err := gh.healthChecker.CheckHealth(c.Request.Context(), name)
+		if err != nil || status.Status == string(domain.ServiceStatusUnhealthy) {
+			unhealthy[name] = status.Status
+		}
+	}
+
+	if len(unhealthy) > 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "not_ready",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"services":  unhealthy,
+		})
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ready",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
 
-	c.JSON(http.StatusOK, health)
+// StartDraining makes readiness fail so load balancers stop sending new traffic
+func (gh *GatewayHandler) StartDraining() {
+	gh.draining.Store(true)
+	gh.logger.Info("Gateway draining, readiness now failing", nil)
 }
 
 // HandleMetrics handles metrics endpoint
 func (gh *GatewayHandler) HandleMetrics(c *gin.Context) {
+	// Services without a health check count as unknown
+	names := gh.configProvider.ListServices()
+	statuses, err := gh.healthChecker.CheckAllServices(c.Request.Context())
+	if err != nil {
+		gh.logger.Error("Failed to read service health", err, nil)
+	}
+	counts := map[string]int{}
+	for _, name := range names {
+		status, checked := statuses[name]
+		if !checked || status.Status == "" {
+			status.Status = string(domain.ServiceStatusUnknown)
+		}
+		counts[status.Status]++
+	}
+
 	metrics := gin.H{
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"gateway": gin.H{
@@ -237,11 +397,17 @@ func (gh *GatewayHandler) HandleMetrics(c *gin.Context) {
 			"errors":  0,
 		},
 		"services": gin.H{
-			"total":   len(gh.configProvider.(*ConfigProvider).config.Services),
-			"healthy": 0, // Would be updated by health checks
+			"total":     len(names),
+			"healthy":   counts[string(domain.ServiceStatusHealthy)],
+			"unhealthy": counts[string(domain.ServiceStatusUnhealthy)],
+			"unknown":   counts[string(domain.ServiceStatusUnknown)],
 		},
 	}
 
+	if gh.metricsCollector != nil {
+		metrics["collected"] = gh.metricsCollector.Snapshot()
+	}
+
 	c.JSON(http.StatusOK, metrics)
 }
 
@@ -253,6 +419,10 @@ func (gh *GatewayHandler) RegisterRoutes(router *gin.Engine) {
 	router.HEAD("/health", gh.HandleHealth)  // Gateway health check (HEAD)
 	router.GET("/healthz", gh.HandleHealth)  // Kubernetes-style alias
 	router.HEAD("/healthz", gh.HandleHealth) // Kubernetes-style alias (HEAD)
+	router.GET("/livez", gh.HandleLivez)     // Liveness: the process is up
+	router.HEAD("/livez", gh.HandleLivez)    // Liveness (HEAD)
+	router.GET("/readyz", gh.HandleReadyz)   // Readiness: critical services healthy, not draining
+	router.HEAD("/readyz", gh.HandleReadyz)  // Readiness (HEAD)
 	router.GET("/metrics", gh.HandleMetrics) // Prometheus metrics
 
 	// Business API routes (versioned, dynamic routing from config.yaml)
@@ -262,32 +432,53 @@ func (gh *GatewayHandler) RegisterRoutes(router *gin.Engine) {
 
 // ConfigProvider implements ports.ConfigProvider interface
 type ConfigProvider struct {
-	config *config.Config
-	logger ports.Logger
+	config     *config.Config
+	discovery  *discovery.Registry
+	transports *upstream.TransportRegistry
+	logger     ports.Logger
+	mutex      sync.RWMutex
 }
 
 // NewConfigProvider creates a new config provider
-func NewConfigProvider(config *config.Config, logger ports.Logger) *ConfigProvider {
+func NewConfigProvider(config *config.Config, discovery *discovery.Registry, transports *upstream.TransportRegistry, logger ports.Logger) *ConfigProvider {
 	return &ConfigProvider{
-		config: config,
-		logger: logger,
+		config:     config,
+		discovery:  discovery,
+		transports: transports,
+		logger:     logger,
 	}
 }
 
 // GetRouteConfig retrieves route configuration for a path and method
 func (cp *ConfigProvider) GetRouteConfig(path string, method string) (*ports.RouteConfig, bool) {
-	for _, route := range cp.config.Routes {
+	cfg := cp.current()
+	for _, route := range cfg.Routes {
 		if cp.matchRoute(route, path, method) {
 			return &ports.RouteConfig{
-				Path:         route.Path,
-				Method:       route.Method,
-				Mode:         route.Mode,
-				Strategy:     route.Strategy,
-				Upstream:     route.Upstream,
-				TargetPath:   route.TargetPath,
-				AuthRequired: route.AuthRequired,
-				Upstreams:    cp.convertUpstreams(route.Upstreams),
-				Metadata:     route.Metadata,
+				Path:            route.Path,
+				Method:          route.Method,
+				Mode:            route.Mode,
+				Strategy:        route.Strategy,
+				Upstream:        route.Upstream,
+				TargetPath:      route.TargetPath,
+				Rewrite:         cp.convertRewrite(route.Rewrite),
+				AuthRequired:    route.AuthRequired,
+				MaxBodySize:     int64(route.MaxBodySize),
+				MaxResponseSize: int64(route.MaxResponseSize),
+				Upload:          cp.convertUpload(route.Upload),
+				Upstreams:       cp.convertUpstreams(route.Upstreams),
+				Compare:         cp.convertCompare(route.Compare),
+				TrafficSplit:    cp.convertTrafficSplit(route.TrafficSplit),
+				Bulkhead:        cp.convertBulkhead(route.Bulkhead),
+				Retry:           cp.convertRetry(route.Retry),
+				Hedge:           cp.convertHedge(route.Hedge),
+				Coalesce:        cp.convertCoalesce(route.Coalesce),
+				Fallback:        cp.convertFallback(route.Fallback),
+				Priority:        route.Priority,
+				Faults:          faultRules(route.Faults),
+				Claims:          cp.convertClaims(route.Claims),
+				Access:          cp.convertAccess(route, cfg.RouteGroups),
+				Metadata:        route.Metadata,
 			}, true
 		}
 	}
@@ -296,19 +487,36 @@ func (cp *ConfigProvider) GetRouteConfig(path string, method string) (*ports.Rou
 
 // GetServiceConfig retrieves service configuration by name
 func (cp *ConfigProvider) GetServiceConfig(serviceName string) (*ports.ServiceInfo, bool) {
-	if service, exists := cp.config.Services[serviceName]; exists {
+	if service, exists := cp.current().Services[serviceName]; exists {
+		// Requests are built on the service URL, keeping its host and base path;
+		// the service transport swaps in a discovered endpoint's address
 		return &ports.ServiceInfo{
-			Name:    serviceName,
-			URL:     service.URL,
-			Timeout: service.Timeout.String(),
+			Name:            serviceName,
+			URL:             service.URL,
+			Timeout:         service.Timeout.String(),
+			MaxResponseSize: int64(service.MaxResponseSize),
+			Endpoints:       cp.discovery.Endpoints(serviceName),
+			Transport:       cp.transports.Transport(serviceName),
+			Critical:        service.Critical,
 		}, true
 	}
 	return nil, false
 }
 
+// ListServices returns the names of the configured services in a stable order
+func (cp *ConfigProvider) ListServices() []string {
+	services := cp.current().Services
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetStrategyConfig retrieves strategy configuration by name
 func (cp *ConfigProvider) GetStrategyConfig(strategyName string) (map[string]interface{}, bool) {
-	if strategy, exists := cp.config.Strategies[strategyName]; exists {
+	if strategy, exists := cp.current().Strategies[strategyName]; exists {
 		result := make(map[string]interface{})
 		result["timeout"] = strategy.Timeout.String()
 		result["parallel_requests"] = strategy.ParallelRequests
@@ -322,10 +530,28 @@ func (cp *ConfigProvider) GetStrategyConfig(strategyName string) (map[string]int
 	return nil, false
 }
 
-// ReloadConfig reloads the configuration
+// ReloadConfig reloads the configuration, keeping the current one if the new one is invalid
 func (cp *ConfigProvider) ReloadConfig() error {
 	newConfig := config.LoadConfig()
+	if err := validateRoutes(newConfig); err != nil {
+		return err
+	}
+	previous := cp.current().Services
+	if err := cp.discovery.Update(newConfig.Services); err != nil {
+		return err
+	}
+	if err := cp.transports.Update(newConfig.Services); err != nil {
+		// Discovery must keep describing the services the transports still serve
+		if rollbackErr := cp.discovery.Update(previous); rollbackErr != nil {
+			cp.logger.Error("Failed to restore service discovery after a rejected reload", rollbackErr, nil)
+		}
+		return err
+	}
+
+	cp.mutex.Lock()
 	cp.config = newConfig
+	cp.mutex.Unlock()
+
 	cp.logger.Info("Configuration reloaded", map[string]interface{}{
 		"routes_count":     len(newConfig.Routes),
 		"services_count":   len(newConfig.Services),
@@ -334,64 +560,138 @@ func (cp *ConfigProvider) ReloadConfig() error {
 	return nil
 }
 
-// matchRoute checks if a route matches the given path and method
-func (cp *ConfigProvider) matchRoute(route config.RouteConfig, path string, method string) bool {
-	// Check method first
-	if route.Method != method && route.Method != "*" {
-		return false
-	}
-
-	// Simple path matching with wildcard support
-	routeParts := strings.Split(strings.Trim(route.Path, "/"), "/")
-	pathParts := strings.Split(strings.Trim(path, "/"), "/")
-
-	cp.logger.Debug("Matching route", map[string]interface{}{
-		"route_path":   route.Path,
-		"request_path": path,
-		"route_parts":  routeParts,
-		"path_parts":   pathParts,
-	})
+// ValidateRoutes checks every route rewrite against the examples declared next to it,
+// every fallback against the configured services, every priority class, route group
+// and fault rule
+func (cp *ConfigProvider) ValidateRoutes() error {
+	return validateRoutes(cp.current())
+}
 
-	// If route ends with wildcard (*), it should match any path that starts with the route prefix
-	if len(routeParts) > 0 && routeParts[len(routeParts)-1] == "*" {
-		// Remove the wildcard from route parts for comparison
-		routePrefix := routeParts[:len(routeParts)-1]
+// validateRoutes runs every route check
+func validateRoutes(cfg *config.Config) error {
+	if err := validateRewrites(cfg); err != nil {
+		return err
+	}
+	if err := validateFallbacks(cfg); err != nil {
+		return err
+	}
+	if err := validatePriorities(cfg); err != nil {
+		return err
+	}
+	if err := validateAccess(cfg); err != nil {
+		return err
+	}
+	return validateFaults(cfg)
+}
 
-		// Path must have at least as many parts as the route prefix (can be equal or more)
-		if len(pathParts) < len(routePrefix) {
-			return false
+// validateRewrites compiles each rewrite rule and runs its examples
+func validateRewrites(cfg *config.Config) error {
+	for _, route := range cfg.Routes {
+		rule := domain.RewriteRule{TargetPath: route.TargetPath}
+		var examples []domain.RewriteExample
+		if route.Rewrite != nil {
+			rule.StripPrefix = route.Rewrite.StripPrefix
+			rule.Regex = route.Rewrite.Regex
+			rule.Replacement = route.Rewrite.Replacement
+			rule.Query = route.Rewrite.Query
+			for _, example := range route.Rewrite.Examples {
+				examples = append(examples, domain.RewriteExample{
+					Request: example.Request,
+					Expect:  example.Expect,
+				})
+			}
 		}
+		if err := rule.Validate(route.Path, examples); err != nil {
+			return fmt.Errorf("route %s %s: %w", route.Method, route.Path, err)
+		}
+	}
+	return nil
+}
 
-		// Check that all prefix parts match
-		for i, routePart := range routePrefix {
-			if strings.HasPrefix(routePart, "{") && strings.HasSuffix(routePart, "}") {
-				// This is a path parameter, skip validation
-				continue
+// validateFallbacks checks that each fallback has a known type and its alternative service exists
+func validateFallbacks(cfg *config.Config) error {
+	for _, route := range cfg.Routes {
+		if route.Fallback == nil {
+			continue
+		}
+		switch route.Fallback.Type {
+		case services.FallbackStatic:
+			if route.Method != "*" && !services.StaticFallbackAllowed(route.Method, route.Fallback.StatusCode) {
+				return fmt.Errorf("route %s %s: static fallback for an unsafe method needs a non-2xx status_code", route.Method, route.Path)
 			}
-			if routePart != pathParts[i] {
-				return false
+		case services.FallbackStale:
+		case services.FallbackService:
+			if _, exists := cfg.Services[route.Fallback.Service]; !exists {
+				return fmt.Errorf("route %s %s: fallback service %q is not configured", route.Method, route.Path, route.Fallback.Service)
 			}
+		default:
+			return fmt.Errorf("route %s %s: unknown fallback type %q", route.Method, route.Path, route.Fallback.Type)
 		}
-
-		return true
 	}
+	return nil
+}
 
-	// Exact match for non-wildcard routes
-	if len(routeParts) != len(pathParts) {
-		return false
+// validatePriorities checks that each route declares a known priority class
+func validatePriorities(cfg *config.Config) error {
+	for _, route := range cfg.Routes {
+		if _, known := priorityClasses[route.Priority]; route.Priority != "" && !known {
+			return fmt.Errorf("route %s %s: unknown priority %q", route.Method, route.Path, route.Priority)
+		}
 	}
+	return nil
+}
 
-	for i, routePart := range routeParts {
-		if strings.HasPrefix(routePart, "{") && strings.HasSuffix(routePart, "}") {
-			// This is a path parameter, skip validation
-			continue
+// validateAccess checks that every route group exists and that routes with role
+// rules authenticate their callers, since roles cannot be checked without a user
+func validateAccess(cfg *config.Config) error {
+	for _, route := range cfg.Routes {
+		group, exists := cfg.RouteGroups[route.Group]
+		if route.Group != "" && !exists {
+			return fmt.Errorf("route %s %s: unknown route group %q", route.Method, route.Path, route.Group)
 		}
-		if routePart != pathParts[i] {
-			return false
+		hasRules := len(route.RequiredRoles) > 0 || len(route.AnyOfRoles) > 0 || len(route.NoneOfRoles) > 0 ||
+			len(group.RequiredRoles) > 0 || len(group.AnyOfRoles) > 0 || len(group.NoneOfRoles) > 0
+		if hasRules && !route.AuthRequired {
+			return fmt.Errorf("route %s %s: role rules need auth_required", route.Method, route.Path)
 		}
 	}
+	return nil
+}
 
-	return true
+// validateFaults checks every route fault rule
+func validateFaults(cfg *config.Config) error {
+	for _, route := range cfg.Routes {
+		for _, rule := range faultRules(route.Faults) {
+			if err := services.ValidateFaultRule(rule); err != nil {
+				return fmt.Errorf("route %s %s: %w", route.Method, route.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// current returns the active configuration snapshot
+func (cp *ConfigProvider) current() *config.Config {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return cp.config
+}
+
+// matchRoute checks if a route matches the given path and method
+func (cp *ConfigProvider) matchRoute(route config.RouteConfig, path string, method string) bool {
+	// Check method first
+	if route.Method != method && route.Method != "*" {
+		return false
+	}
+
+	cp.logger.Debug("Matching route", map[string]interface{}{
+		"route_path":   route.Path,
+		"request_path": path,
+	})
+
+	// Path parameters and a trailing wildcard are matched by the rewrite engine
+	_, matched := domain.MatchPath(route.Path, path)
+	return matched
 }
 
 // convertUpstreams converts config upstreams to ports upstreams
@@ -406,3 +706,190 @@ func (cp *ConfigProvider) convertUpstreams(upstreams []config.UpstreamConfig) []
 	}
 	return result
 }
+
+// convertCompare converts config compare settings to ports compare settings
+func (cp *ConfigProvider) convertCompare(compare *config.CompareConfig) *ports.CompareConfig {
+	if compare == nil || compare.Candidate == "" {
+		return nil
+	}
+	return &ports.CompareConfig{
+		Candidate:     compare.Candidate,
+		CandidatePath: compare.CandidatePath,
+		IgnoreFields:  compare.IgnoreFields,
+		SampleRate:    compare.SampleRate,
+		Timeout:       compare.Timeout,
+	}
+}
+
+// convertTrafficSplit converts config traffic split settings to ports settings
+func (cp *ConfigProvider) convertTrafficSplit(split *config.TrafficSplitConfig) *ports.TrafficSplitConfig {
+	if split == nil || len(split.Variants) == 0 {
+		return nil
+	}
+	variants := make([]ports.TrafficVariant, len(split.Variants))
+	for i, variant := range split.Variants {
+		variants[i] = ports.TrafficVariant{
+			Name:       variant.Name,
+			Upstream:   variant.Upstream,
+			TargetPath: variant.TargetPath,
+			Weight:     variant.Weight,
+			Canary:     variant.Canary,
+		}
+	}
+	return &ports.TrafficSplitConfig{
+		Header:   split.Header,
+		Cookie:   split.Cookie,
+		Sticky:   split.Sticky,
+		Variants: variants,
+	}
+}
+
+// convertRewrite converts config rewrite settings to ports rewrite settings
+func (cp *ConfigProvider) convertRewrite(rewrite *config.RewriteConfig) *ports.RewriteConfig {
+	if rewrite == nil {
+		return nil
+	}
+	return &ports.RewriteConfig{
+		StripPrefix: rewrite.StripPrefix,
+		Regex:       rewrite.Regex,
+		Replacement: rewrite.Replacement,
+		Query:       rewrite.Query,
+	}
+}
+
+// convertBulkhead converts config bulkhead limits to ports bulkhead limits
+func (cp *ConfigProvider) convertBulkhead(bulkhead *config.BulkheadConfig) *ports.BulkheadConfig {
+	if bulkhead == nil || bulkhead.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &ports.BulkheadConfig{
+		MaxConcurrent: bulkhead.MaxConcurrent,
+		MaxQueue:      bulkhead.MaxQueue,
+		MaxWait:       bulkhead.MaxWait,
+	}
+}
+
+// convertRetry converts config retry settings to a ports retry policy
+func (cp *ConfigProvider) convertRetry(retry *config.RetryConfig) *ports.RetryPolicy {
+	if retry == nil || retry.MaxAttempts <= 1 {
+		return nil
+	}
+	return &ports.RetryPolicy{
+		MaxAttempts: retry.MaxAttempts,
+		RetryOn:     retry.RetryOn,
+		Backoff:     retry.Backoff,
+	}
+}
+
+// convertHedge converts config hedge settings to a ports hedge policy
+func (cp *ConfigProvider) convertHedge(hedge *config.HedgeConfig) *ports.HedgePolicy {
+	if hedge == nil {
+		return nil
+	}
+	return &ports.HedgePolicy{
+		Delay:      hedge.Delay,
+		Percentile: hedge.Percentile,
+		Budget:     hedge.Budget,
+	}
+}
+
+// convertCoalesce converts config coalescing settings to ports format
+func (cp *ConfigProvider) convertCoalesce(coalesce *config.CoalesceConfig) *ports.CoalesceConfig {
+	if coalesce == nil {
+		return nil
+	}
+	return &ports.CoalesceConfig{
+		QueryParams: coalesce.QueryParams,
+		Scope:       coalesce.Scope,
+	}
+}
+
+// convertClaims converts a route's claims policy to ports format
+func (cp *ConfigProvider) convertClaims(claims *config.ClaimsPolicyConfig) *ports.ClaimsPolicy {
+	if claims == nil {
+		return nil
+	}
+	return &ports.ClaimsPolicy{
+		Issuer:         claims.Issuer,
+		Audiences:      claims.Audiences,
+		RequireNBF:     claims.RequireNBF,
+		RequireIAT:     claims.RequireIAT,
+		MaxAge:         claims.MaxAge,
+		ClockSkew:      claims.ClockSkew,
+		RequiredClaims: claims.RequiredClaims,
+	}
+}
+
+// convertAccess merges a route's role rules with those of its group; routes
+// without any role rules get no access policy
+func (cp *ConfigProvider) convertAccess(route config.RouteConfig, groups map[string]config.RouteGroupConfig) *ports.AccessPolicy {
+	group := groups[route.Group]
+	access := &ports.AccessPolicy{
+		Group:         route.Group,
+		RequiredRoles: appendRoles(group.RequiredRoles, route.RequiredRoles),
+		AnyOfRoles:    group.AnyOfRoles,
+		NoneOfRoles:   appendRoles(group.NoneOfRoles, route.NoneOfRoles),
+	}
+	if len(route.AnyOfRoles) > 0 {
+		access.AnyOfRoles = route.AnyOfRoles
+	}
+	if len(access.RequiredRoles) == 0 && len(access.AnyOfRoles) == 0 && len(access.NoneOfRoles) == 0 {
+		return nil
+	}
+	return access
+}
+
+// appendRoles joins two role lists without aliasing either
+func appendRoles(base, extra []string) []string {
+	if len(base) == 0 && len(extra) == 0 {
+		return nil
+	}
+	return append(append(make([]string, 0, len(base)+len(extra)), base...), extra...)
+}
+
+// convertFallback converts config fallback settings to ports format
+func (cp *ConfigProvider) convertFallback(fallback *config.FallbackConfig) *ports.FallbackConfig {
+	if fallback == nil {
+		return nil
+	}
+	return &ports.FallbackConfig{
+		Type:       fallback.Type,
+		StatusCode: fallback.StatusCode,
+		Body:       fallback.Body,
+		MaxAge:     fallback.MaxAge,
+		Scope:      fallback.Scope,
+		Service:    fallback.Service,
+		TargetPath: fallback.TargetPath,
+	}
+}
+
+// faultRules converts config fault settings to ports fault rules
+func faultRules(faults []config.FaultConfig) []ports.FaultRule {
+	if len(faults) == 0 {
+		return nil
+	}
+	rules := make([]ports.FaultRule, 0, len(faults))
+	for _, fault := range faults {
+		rules = append(rules, ports.FaultRule{
+			Type:       fault.Type,
+			Delay:      fault.Delay,
+			Status:     fault.Status,
+			Bytes:      fault.Bytes,
+			Percentage: fault.Percentage,
+			Header:     fault.Header,
+		})
+	}
+	return rules
+}
+
+// convertUpload converts config upload settings to a ports upload policy
+func (cp *ConfigProvider) convertUpload(upload *config.UploadConfig) *ports.UploadPolicy {
+	if upload == nil {
+		return nil
+	}
+	return &ports.UploadPolicy{
+		AllowedTypes: upload.AllowedTypes,
+		MaxFiles:     upload.MaxFiles,
+		MaxFileSize:  int64(upload.MaxFileSize),
+	}
+}
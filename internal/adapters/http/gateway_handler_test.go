@@ -0,0 +1,243 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/discovery"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/upstream"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// TestShippedRoutesValidate runs the route checks, including the rewrite examples
+// declared next to each route, against the configuration shipped with the gateway
+func TestShippedRoutesValidate(t *testing.T) {
+	for _, file := range []string{"../../../config.yaml", "../../../config.yml"} {
+		t.Run(file, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", file)
+			cfg := config.LoadConfig()
+			if len(cfg.Routes) == 0 {
+				t.Fatalf("no routes loaded from %s", file)
+			}
+			if err := validateRoutes(cfg); err != nil {
+				t.Errorf("validateRoutes() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestGetServiceConfigKeepsServiceURL checks that discovered endpoints do not replace
+// the service URL, whose host and base path requests are built on
+func TestGetServiceConfigKeepsServiceURL(t *testing.T) {
+	log := logger.NewLogger("error", "json", "test")
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"plants": {
+			URL:       "http://plants.internal:8080/base",
+			Discovery: &config.DiscoveryConfig{Type: discovery.TypeStatic, Endpoints: []string{"http://10.0.0.7:8080", "http://10.0.0.8:8080"}},
+		},
+	}}
+	registry := discovery.NewRegistry(log)
+	if err := registry.Update(cfg.Services); err != nil {
+		t.Fatal(err)
+	}
+	provider := NewConfigProvider(cfg, registry, upstream.NewTransportRegistry(registry, nil, metrics.NewCollector(), log), log)
+
+	info, found := provider.GetServiceConfig("plants")
+	if !found {
+		t.Fatal("service not found")
+	}
+	if info.URL != "http://plants.internal:8080/base" {
+		t.Errorf("URL = %s, want the configured service URL", info.URL)
+	}
+	if len(info.Endpoints) != 2 {
+		t.Errorf("endpoints = %v, want both discovered instances", info.Endpoints)
+	}
+}
+
+func TestReloadConfigKeepsDiscoveryAndTransportsTogether(t *testing.T) {
+	log := logger.NewLogger("error", "json", "test")
+	plants := func(endpoint string) config.ServiceConfig {
+		return config.ServiceConfig{
+			URL:       "http://plants:8080",
+			Discovery: &config.DiscoveryConfig{Type: discovery.TypeStatic, Endpoints: []string{endpoint}},
+		}
+	}
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{"plants": plants("http://10.0.0.1:8080")}}
+	registry := discovery.NewRegistry(log)
+	if err := registry.Update(cfg.Services); err != nil {
+		t.Fatal(err)
+	}
+	transports := upstream.NewTransportRegistry(registry, nil, metrics.NewCollector(), log)
+	defer transports.Stop()
+	if err := transports.Update(cfg.Services); err != nil {
+		t.Fatal(err)
+	}
+	provider := NewConfigProvider(cfg, registry, transports, log)
+
+	reload := func(yaml string) error {
+		file := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(file, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("CONFIG_FILE", file)
+		return provider.ReloadConfig()
+	}
+
+	// Valid discovery but an unreadable CA: the transports refuse the reload
+	err := reload(`
+services:
+  plants:
+    url: "https://plants:8443"
+    discovery:
+      type: static
+      endpoints: ["https://10.0.0.2:8443"]
+    tls:
+      ca_file: "/nonexistent/ca.pem"
+`)
+	if err == nil {
+		t.Fatal("ReloadConfig() accepted a service with a missing CA file")
+	}
+	if got := registry.Endpoints("plants"); len(got) != 1 || got[0] != "http://10.0.0.1:8080" {
+		t.Errorf("endpoints after a rejected reload = %v, want the previous ones", got)
+	}
+
+	if err := reload(`
+services:
+  plants:
+    url: "http://plants:8080"
+    discovery:
+      type: static
+      endpoints: ["http://10.0.0.3:8080"]
+`); err != nil {
+		t.Fatalf("ReloadConfig() error = %v", err)
+	}
+	if got := registry.Endpoints("plants"); len(got) != 1 || got[0] != "http://10.0.0.3:8080" {
+		t.Errorf("endpoints after a reload = %v, want the new ones", got)
+	}
+}
+
+// serviceTable is a config provider that only knows a fixed set of services
+type serviceTable map[string]*ports.ServiceInfo
+
+func (st serviceTable) GetRouteConfig(string, string) (*ports.RouteConfig, bool) { return nil, false }
+func (st serviceTable) GetServiceConfig(name string) (*ports.ServiceInfo, bool) {
+	service, found := st[name]
+	return service, found
+}
+func (st serviceTable) ListServices() []string {
+	names := make([]string, 0, len(st))
+	for name := range st {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+func (st serviceTable) GetStrategyConfig(string) (map[string]interface{}, bool) { return nil, false }
+func (st serviceTable) ReloadConfig() error                                     { return nil }
+
+// healthTable is a health checker reporting fixed statuses for the checked services
+type healthTable map[string]string
+
+func (ht healthTable) CheckHealth(_ context.Context, name string) (ports.HealthStatus, error) {
+	if status, checked := ht[name]; checked {
+		return ports.HealthStatus{Status: status}, nil
+	}
+	return ports.HealthStatus{Status: string(domain.ServiceStatusUnknown), Message: "health check not configured"}, nil
+}
+func (ht healthTable) CheckAllServices(context.Context) (map[string]ports.HealthStatus, error) {
+	statuses := make(map[string]ports.HealthStatus, len(ht))
+	for name, status := range ht {
+		statuses[name] = ports.HealthStatus{Status: status}
+	}
+	return statuses, nil
+}
+
+func TestHandleMetricsServiceHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	services := serviceTable{"auth": {}, "plants": {}, "analytics": {}, "reports": {}}
+	health := healthTable{"auth": "healthy", "plants": "healthy", "analytics": "unhealthy"}
+	handler := NewGatewayHandler(nil, services, health, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+	router := gin.New()
+	router.GET("/metrics", handler.HandleMetrics)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	var body struct {
+		Services map[string]int `json:"services"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"total": 4, "healthy": 2, "unhealthy": 1, "unknown": 1}
+	if !reflect.DeepEqual(body.Services, want) {
+		t.Errorf("services = %v, want %v", body.Services, want)
+	}
+}
+
+func TestHandleReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	services := serviceTable{
+		"auth":      {Critical: true},
+		"plants":    {Critical: true},
+		"analytics": {},
+	}
+
+	tests := []struct {
+		name         string
+		health       healthTable
+		draining     bool
+		wantCode     int
+		wantStatus   string
+		wantServices map[string]interface{}
+	}{
+		{"critical services healthy", healthTable{"auth": "healthy", "plants": "healthy", "analytics": "unhealthy"}, false, http.StatusOK, "ready", nil},
+		{"critical service without a health check", healthTable{"auth": "healthy"}, false, http.StatusOK, "ready", nil},
+		{"critical service unhealthy", healthTable{"auth": "healthy", "plants": "unhealthy"}, false, http.StatusServiceUnavailable, "not_ready", map[string]interface{}{"plants": "unhealthy"}},
+		{"draining", healthTable{"auth": "healthy", "plants": "healthy"}, true, http.StatusServiceUnavailable, "draining", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewGatewayHandler(nil, services, tt.health, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+			if tt.draining {
+				handler.StartDraining()
+			}
+			router := gin.New()
+			router.GET("/readyz", handler.HandleReadyz)
+			router.GET("/livez", handler.HandleLivez)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest("GET", "/readyz", nil))
+			var body struct {
+				Status   string                 `json:"status"`
+				Services map[string]interface{} `json:"services"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if recorder.Code != tt.wantCode || body.Status != tt.wantStatus || !reflect.DeepEqual(body.Services, tt.wantServices) {
+				t.Errorf("readyz = %d %s %v, want %d %s %v", recorder.Code, body.Status, body.Services, tt.wantCode, tt.wantStatus, tt.wantServices)
+			}
+
+			// Liveness never depends on upstreams or draining
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest("GET", "/livez", nil))
+			if recorder.Code != http.StatusOK {
+				t.Errorf("livez = %d, want 200", recorder.Code)
+			}
+		})
+	}
+}
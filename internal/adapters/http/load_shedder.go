@@ -0,0 +1,185 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Route priority classes, from never shed to shed first
+const (
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityNormal   = "normal"
+	PriorityLow      = "low"
+)
+
+// priorityClass sets the overload pressure at which a class is shed and how long
+// its clients are asked to wait before retrying
+type priorityClass struct {
+	shedAt     float64
+	retryAfter int
+}
+
+// priorityClasses maps each class to its shedding threshold. Critical traffic,
+// such as health checks and login, is never shed.
+var priorityClasses = map[string]priorityClass{
+	PriorityCritical: {shedAt: math.Inf(1)},
+	PriorityHigh:     {shedAt: 1.0, retryAfter: 1},
+	PriorityNormal:   {shedAt: 0.9, retryAfter: 2},
+	PriorityLow:      {shedAt: 0.8, retryAfter: 5},
+}
+
+const (
+	// latencyWeight smooths the average request latency seen by the overload detector
+	latencyWeight = 0.05
+	// latencyHalfLife lets the average fade while no requests complete, so shedding
+	// everything cannot keep the gateway looking overloaded
+	latencyHalfLife = 5 * time.Second
+)
+
+// LoadShedder rejects lower-priority requests first when the gateway is overloaded.
+// Overload pressure is the highest of in-flight requests, goroutines and average
+// latency, each relative to its configured limit; 1.0 means a limit is reached.
+type LoadShedder struct {
+	config         config.LoadSheddingConfig
+	inFlight       int64
+	latency        float64
+	latencyAt      time.Time
+	latencyMutex   sync.Mutex
+	configProvider ports.ConfigProvider
+	metrics        ports.MetricsCollector
+	logger         ports.Logger
+}
+
+// NewLoadShedder creates a new load shedder
+func NewLoadShedder(cfg config.LoadSheddingConfig, configProvider ports.ConfigProvider, metrics ports.MetricsCollector, logger ports.Logger) *LoadShedder {
+	return &LoadShedder{
+		config:         cfg,
+		configProvider: configProvider,
+		metrics:        metrics,
+		logger:         logger,
+	}
+}
+
+// Middleware sheds requests whose priority class is below the current pressure
+// and tracks the in-flight count and latency of the requests it lets through
+func (ls *LoadShedder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		priority := ls.priorityOf(c.Request)
+		class := priorityClasses[priority]
+
+		pressure := ls.pressure()
+		if pressure >= class.shedAt {
+			ls.shed(c, priority, class, pressure)
+			return
+		}
+
+		inFlight := atomic.AddInt64(&ls.inFlight, 1)
+		ls.metrics.SetGauge("gateway_in_flight_requests", float64(inFlight), nil)
+		start := time.Now()
+		defer func() {
+			ls.recordLatency(time.Since(start))
+			ls.metrics.SetGauge("gateway_in_flight_requests", float64(atomic.AddInt64(&ls.inFlight, -1)), nil)
+		}()
+
+		c.Next()
+	}
+}
+
+// priorityOf returns the priority class of the route serving the request. The
+// gateway's own probe and metrics endpoints are critical; any other request that
+// matches no configured route, such as a flood of unknown paths, is shed first.
+func (ls *LoadShedder) priorityOf(req *http.Request) string {
+	if isGatewayEndpoint(req.URL.Path) {
+		return PriorityCritical
+	}
+	routeConfig, found := ls.configProvider.GetRouteConfig(req.URL.Path, req.Method)
+	if !found {
+		return PriorityLow
+	}
+	if _, known := priorityClasses[routeConfig.Priority]; !known {
+		return PriorityNormal
+	}
+	return routeConfig.Priority
+}
+
+// isGatewayEndpoint reports whether the path is one of the gateway's own health,
+// probe or metrics endpoints
+func isGatewayEndpoint(path string) bool {
+	switch path {
+	case "/livez", "/readyz", "/metrics":
+		return true
+	}
+	return strings.HasPrefix(path, "/health")
+}
+
+// pressure returns how close the gateway is to its limits
+func (ls *LoadShedder) pressure() float64 {
+	pressure := 0.0
+	if ls.config.MaxInFlight > 0 {
+		pressure = math.Max(pressure, float64(atomic.LoadInt64(&ls.inFlight))/float64(ls.config.MaxInFlight))
+	}
+	if ls.config.MaxGoroutines > 0 {
+		pressure = math.Max(pressure, float64(runtime.NumGoroutine())/float64(ls.config.MaxGoroutines))
+	}
+	if ls.config.TargetLatency > 0 {
+		pressure = math.Max(pressure, ls.averageLatency()/float64(ls.config.TargetLatency))
+	}
+	ls.metrics.SetGauge("gateway_overload_pressure", pressure, nil)
+	return pressure
+}
+
+// recordLatency folds a completed request into the average latency
+func (ls *LoadShedder) recordLatency(latency time.Duration) {
+	ls.latencyMutex.Lock()
+	defer ls.latencyMutex.Unlock()
+	ls.latency = ls.decayedLatency(time.Now())
+	ls.latency += (float64(latency) - ls.latency) * latencyWeight
+	ls.latencyAt = time.Now()
+}
+
+// averageLatency returns the average latency, faded by the time since the last sample
+func (ls *LoadShedder) averageLatency() float64 {
+	ls.latencyMutex.Lock()
+	defer ls.latencyMutex.Unlock()
+	return ls.decayedLatency(time.Now())
+}
+
+// decayedLatency halves the average for every latencyHalfLife without samples;
+// callers hold latencyMutex
+func (ls *LoadShedder) decayedLatency(now time.Time) float64 {
+	idle := now.Sub(ls.latencyAt)
+	if ls.latencyAt.IsZero() || idle <= 0 {
+		return ls.latency
+	}
+	return ls.latency * math.Pow(0.5, float64(idle)/float64(latencyHalfLife))
+}
+
+// shed rejects the request with 503 Service Unavailable, naming the shed class
+func (ls *LoadShedder) shed(c *gin.Context, priority string, class priorityClass, pressure float64) {
+	ls.metrics.IncrementCounter("gateway_load_shed_total", map[string]string{"priority": priority})
+	ls.logger.Warn("Request shed under overload", map[string]interface{}{
+		"path":     c.Request.URL.Path,
+		"method":   c.Request.Method,
+		"priority": priority,
+		"pressure": pressure,
+	})
+
+	c.Header("Retry-After", strconv.Itoa(class.retryAfter))
+	c.Header("X-Gateway-Shed-Priority", priority)
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+		"error":    "Gateway overloaded",
+		"code":     "LOAD_SHED",
+		"priority": priority,
+	})
+}
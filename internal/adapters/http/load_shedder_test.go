@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// routeTable is a config provider that only knows a fixed set of routes
+type routeTable map[string]*ports.RouteConfig
+
+func (rt routeTable) GetRouteConfig(path string, method string) (*ports.RouteConfig, bool) {
+	route, found := rt[method+" "+path]
+	return route, found
+}
+func (rt routeTable) GetServiceConfig(string) (*ports.ServiceInfo, bool)      { return nil, false }
+func (rt routeTable) ListServices() []string                                  { return nil }
+func (rt routeTable) GetStrategyConfig(string) (map[string]interface{}, bool) { return nil, false }
+func (rt routeTable) ReloadConfig() error                                     { return nil }
+
+func TestLoadShedderPriorityOf(t *testing.T) {
+	routes := routeTable{
+		"POST /api/v1/auth/login": {Priority: PriorityCritical},
+		"GET /api/v1/plants":      {Priority: PriorityHigh},
+		"GET /api/v1/reports":     {Priority: PriorityLow},
+		"GET /api/v1/analytics":   {},
+		"GET /api/v1/legacy":      {Priority: "urgent"},
+	}
+	shedder := NewLoadShedder(config.LoadSheddingConfig{}, routes, metrics.NewCollector(), nil)
+
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{"GET", "/health", PriorityCritical},
+		{"GET", "/health/services", PriorityCritical},
+		{"GET", "/livez", PriorityCritical},
+		{"GET", "/readyz", PriorityCritical},
+		{"GET", "/metrics", PriorityCritical},
+		{"POST", "/api/v1/auth/login", PriorityCritical},
+		{"GET", "/api/v1/plants", PriorityHigh},
+		{"GET", "/api/v1/reports", PriorityLow},
+		{"GET", "/api/v1/analytics", PriorityNormal},
+		{"GET", "/api/v1/legacy", PriorityNormal},
+		{"GET", "/api/v1/unknown", PriorityLow},
+		{"GET", "/metricsx", PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if got := shedder.priorityOf(req); got != tt.want {
+				t.Errorf("priorityOf(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
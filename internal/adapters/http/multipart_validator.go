@@ -0,0 +1,194 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at
+const sniffLength = 512
+
+// multipartValidator passes a multipart body through unchanged while a parser
+// inspects a copy of the stream. When the parser rejects the upload, the next
+// read fails so the upstream request is aborted before the body completes.
+type multipartValidator struct {
+	src    io.ReadCloser
+	pw     *io.PipeWriter
+	done   chan struct{}
+	err    error
+	mutex  sync.Mutex
+	closed bool
+}
+
+// newMultipartValidator wraps a multipart body with streaming validation
+func newMultipartValidator(src io.ReadCloser, boundary string, policy ports.UploadPolicy) *multipartValidator {
+	pr, pw := io.Pipe()
+	v := &multipartValidator{
+		src:  src,
+		pw:   pw,
+		done: make(chan struct{}),
+	}
+	go v.validate(pr, boundary, policy)
+	return v
+}
+
+// Read reads from the client body and feeds the parser before handing bytes on
+func (v *multipartValidator) Read(p []byte) (int, error) {
+	n, err := v.src.Read(p)
+	if n > 0 {
+		if _, werr := v.pw.Write(p[:n]); werr != nil {
+			return 0, v.rejection(werr)
+		}
+	}
+
+	if err != nil {
+		// Let the parser finish before reporting the end of the body so that a
+		// violation in the last part still aborts the upstream request
+		v.pw.CloseWithError(err)
+		<-v.done
+		if err == io.EOF {
+			if rejectErr := v.result(); rejectErr != nil {
+				return n, rejectErr
+			}
+		}
+	}
+	return n, err
+}
+
+// Close closes the client body and stops the parser
+func (v *multipartValidator) Close() error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.closed {
+		return nil
+	}
+	v.closed = true
+	v.pw.CloseWithError(io.ErrClosedPipe)
+	return v.src.Close()
+}
+
+// validate parses the mirrored stream and checks each part against the policy
+func (v *multipartValidator) validate(pr *io.PipeReader, boundary string, policy ports.UploadPolicy) {
+	defer close(v.done)
+
+	err := checkParts(multipart.NewReader(pr, boundary), policy)
+	if err != nil {
+		// Failures reading the client body itself are surfaced by Read; anything
+		// else the parser trips over at the end of the body is a malformed upload
+		var uploadErr *domain.UploadError
+		if !errors.As(err, &uploadErr) {
+			err = domain.NewUploadError(http.StatusBadRequest, "MALFORMED_MULTIPART", err.Error())
+		}
+		v.mutex.Lock()
+		v.err = err
+		v.mutex.Unlock()
+		pr.CloseWithError(err)
+		return
+	}
+
+	// Drain the epilogue so writers never block on a finished parser
+	_, _ = io.Copy(io.Discard, pr)
+}
+
+// checkParts enforces the file count, size and sniffed content type limits
+func checkParts(reader *multipart.Reader, policy ports.UploadPolicy) error {
+	files := 0
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if part.FileName() == "" {
+			_, err := io.Copy(io.Discard, part)
+			part.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		files++
+		if policy.MaxFiles > 0 && files > policy.MaxFiles {
+			return domain.NewUploadError(http.StatusBadRequest, "TOO_MANY_FILES",
+				fmt.Sprintf("at most %d file(s) allowed", policy.MaxFiles))
+		}
+
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(part, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		if len(policy.AllowedTypes) > 0 {
+			detected := http.DetectContentType(head[:n])
+			if !isAllowedType(detected, policy.AllowedTypes) {
+				return domain.NewUploadError(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+					fmt.Sprintf("file %q has disallowed type %s", part.FileName(), detected))
+			}
+		}
+
+		size := int64(n)
+		if policy.MaxFileSize > 0 {
+			rest, err := io.CopyN(io.Discard, part, policy.MaxFileSize-size+1)
+			if err != nil && err != io.EOF {
+				return err
+			}
+			size += rest
+			if size > policy.MaxFileSize {
+				return domain.NewUploadError(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE",
+					fmt.Sprintf("file %q exceeds %d bytes", part.FileName(), policy.MaxFileSize))
+			}
+		}
+		if _, err := io.Copy(io.Discard, part); err != nil {
+			return err
+		}
+		part.Close()
+	}
+}
+
+// rejection returns the validation error behind a failed pipe write
+func (v *multipartValidator) rejection(writeErr error) error {
+	<-v.done
+	if err := v.result(); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// result returns the validation error, if any
+func (v *multipartValidator) result() error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.err
+}
+
+// isAllowedType checks a sniffed content type against the allowed list
+func isAllowedType(detected string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(detected)
+	if err != nil {
+		mediaType = detected
+	}
+	for _, allowedType := range allowed {
+		allowedType = strings.ToLower(strings.TrimSpace(allowedType))
+		if allowedType == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowedType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowedType, "*")) {
+			return true
+		}
+	}
+	return false
+}
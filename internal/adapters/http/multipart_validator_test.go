@@ -0,0 +1,138 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// pngHeader is enough for http.DetectContentType to report image/png
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestMultipartValidator(t *testing.T) {
+	type part struct {
+		field, filename, content string
+	}
+	png := func(size int) string { return pngHeader + strings.Repeat("x", size-len(pngHeader)) }
+
+	tests := []struct {
+		name     string
+		parts    []part
+		truncate bool
+		policy   ports.UploadPolicy
+		wantCode string // empty when the upload passes
+	}{
+		{
+			name:   "allowed image",
+			parts:  []part{{"photo", "plant.png", png(1024)}},
+			policy: ports.UploadPolicy{AllowedTypes: []string{"image/png"}, MaxFiles: 1, MaxFileSize: 2048},
+		},
+		{
+			name:   "wildcard type",
+			parts:  []part{{"photo", "plant.png", png(64)}},
+			policy: ports.UploadPolicy{AllowedTypes: []string{" Image/* "}},
+		},
+		{
+			name:   "form fields are not files",
+			parts:  []part{{"name", "", "ficus"}, {"notes", "", "water weekly"}, {"photo", "plant.png", png(64)}},
+			policy: ports.UploadPolicy{AllowedTypes: []string{"image/png"}, MaxFiles: 1},
+		},
+		{
+			name:     "type sniffed from content, not the file name",
+			parts:    []part{{"photo", "plant.png", "#!/bin/sh\necho hi\n"}},
+			policy:   ports.UploadPolicy{AllowedTypes: []string{"image/png"}},
+			wantCode: "UNSUPPORTED_MEDIA_TYPE",
+		},
+		{
+			name:     "too many files",
+			parts:    []part{{"a", "a.png", png(64)}, {"b", "b.png", png(64)}},
+			policy:   ports.UploadPolicy{MaxFiles: 1},
+			wantCode: "TOO_MANY_FILES",
+		},
+		{
+			name:     "file too large",
+			parts:    []part{{"photo", "plant.png", png(4096)}},
+			policy:   ports.UploadPolicy{MaxFileSize: 1024},
+			wantCode: "FILE_TOO_LARGE",
+		},
+		{
+			name:   "file exactly at the size limit",
+			parts:  []part{{"photo", "plant.png", png(1024)}},
+			policy: ports.UploadPolicy{MaxFileSize: 1024},
+		},
+		{
+			name:     "violation in the last part",
+			parts:    []part{{"a", "a.png", png(64)}, {"b", "b.png", png(4096)}},
+			policy:   ports.UploadPolicy{MaxFileSize: 1024},
+			wantCode: "FILE_TOO_LARGE",
+		},
+		{
+			name:     "missing closing boundary",
+			parts:    []part{{"photo", "plant.png", png(64)}},
+			truncate: true,
+			wantCode: "MALFORMED_MULTIPART",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			for _, p := range tt.parts {
+				var w io.Writer
+				var err error
+				if p.filename == "" {
+					w, err = writer.CreateFormField(p.field)
+				} else {
+					w, err = writer.CreateFormFile(p.field, p.filename)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				io.WriteString(w, p.content)
+			}
+			if !tt.truncate {
+				writer.Close()
+			}
+			sent := body.Bytes()
+
+			validator := newMultipartValidator(io.NopCloser(bytes.NewReader(sent)), writer.Boundary(), tt.policy)
+			defer validator.Close()
+			received, err := io.ReadAll(validator)
+
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("read error = %v, want nil", err)
+				}
+				if !bytes.Equal(received, sent) {
+					t.Error("body was changed on its way through the validator")
+				}
+				return
+			}
+			var uploadErr *domain.UploadError
+			if !errors.As(err, &uploadErr) || uploadErr.Code != tt.wantCode {
+				t.Errorf("read error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMultipartValidatorCloseStopsParser(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	validator := newMultipartValidator(reader, "boundary", ports.UploadPolicy{})
+	if err := validator.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	<-validator.done
+	if err := validator.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// PathNormalizer cleans incoming request paths before they are matched against routes
+type PathNormalizer struct {
+	trailingSlash string
+	logger        ports.Logger
+}
+
+// NewPathNormalizer creates a new path normalizer
+func NewPathNormalizer(trailingSlash string, logger ports.Logger) *PathNormalizer {
+	return &PathNormalizer{
+		trailingSlash: trailingSlash,
+		logger:        logger,
+	}
+}
+
+// Middleware rejects unsafe paths and rewrites the request to its normalised path
+func (pn *PathNormalizer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		escapedPath := c.Request.URL.EscapedPath()
+		normalized, err := domain.NormalizePath(escapedPath)
+		if err != nil {
+			pn.logger.Warn("Unsafe request path rejected", map[string]interface{}{
+				"path":   escapedPath,
+				"method": c.Request.Method,
+				"error":  err.Error(),
+			})
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request path",
+				"code":  "INVALID_PATH",
+			})
+			return
+		}
+
+		if normalized != "/" && strings.HasSuffix(normalized, "/") {
+			switch pn.trailingSlash {
+			case domain.TrailingSlashRedirect:
+				location := url.URL{Path: strings.TrimSuffix(normalized, "/"), RawQuery: c.Request.URL.RawQuery}
+				c.Redirect(http.StatusPermanentRedirect, location.String())
+				c.Abort()
+				return
+			case domain.TrailingSlashPreserve:
+			default:
+				normalized = strings.TrimSuffix(normalized, "/")
+			}
+		}
+
+		if normalized != c.Request.URL.Path {
+			pn.logger.Debug("Request path normalised", map[string]interface{}{
+				"original":   escapedPath,
+				"normalized": normalized,
+			})
+			c.Request.URL.Path = normalized
+			c.Request.URL.RawPath = ""
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// RequestLimiter enforces request body size and JSON nesting limits
+type RequestLimiter struct {
+	maxBodySize    int64
+	maxJSONDepth   int
+	configProvider ports.ConfigProvider
+	logger         ports.Logger
+}
+
+// NewRequestLimiter creates a new request limiter
+func NewRequestLimiter(maxBodySize int64, maxJSONDepth int, configProvider ports.ConfigProvider, logger ports.Logger) *RequestLimiter {
+	return &RequestLimiter{
+		maxBodySize:    maxBodySize,
+		maxJSONDepth:   maxJSONDepth,
+		configProvider: configProvider,
+		logger:         logger,
+	}
+}
+
+// Middleware rejects oversized requests early and caps the body reader for the rest
+func (rl *RequestLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		limit := rl.limitFor(c.Request)
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		// Reject up front when the client announces a body that is too large
+		if c.Request.ContentLength > limit {
+			rl.reject(c, limit, c.Request.ContentLength)
+			return
+		}
+
+		// Chunked or unannounced bodies are capped while being read
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		if rl.maxJSONDepth > 0 && strings.Contains(strings.ToLower(c.GetHeader("Content-Type")), "application/json") {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				if IsBodyTooLarge(err) {
+					rl.reject(c, limit, -1)
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to read request body",
+				})
+				return
+			}
+
+			if err := checkJSONDepth(body, rl.maxJSONDepth); err != nil {
+				rl.logger.Warn("Request JSON nesting too deep", map[string]interface{}{
+					"path":      c.Request.URL.Path,
+					"method":    c.Request.Method,
+					"max_depth": rl.maxJSONDepth,
+				})
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error":     "Request JSON nesting too deep",
+					"code":      "JSON_TOO_DEEP",
+					"max_depth": rl.maxJSONDepth,
+				})
+				return
+			}
+
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+	}
+}
+
+// limitFor returns the body size limit of the route serving the request
+func (rl *RequestLimiter) limitFor(req *http.Request) int64 {
+	if routeConfig, found := rl.configProvider.GetRouteConfig(req.URL.Path, req.Method); found && routeConfig.MaxBodySize > 0 {
+		return routeConfig.MaxBodySize
+	}
+	return rl.maxBodySize
+}
+
+// reject aborts the request with 413 Request Entity Too Large
+func (rl *RequestLimiter) reject(c *gin.Context, limit int64, contentLength int64) {
+	rl.logger.Warn("Request body too large", map[string]interface{}{
+		"path":           c.Request.URL.Path,
+		"method":         c.Request.Method,
+		"content_length": contentLength,
+		"max_bytes":      limit,
+	})
+	// The rest of the body is not read, so the connection must not be reused
+	c.Header("Connection", "close")
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":     "Request body too large",
+		"code":      "BODY_TOO_LARGE",
+		"max_bytes": limit,
+	})
+}
+
+// IsBodyTooLarge reports whether an error was caused by exceeding the body size limit
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// checkJSONDepth verifies that a JSON document does not nest deeper than maxDepth
+func checkJSONDepth(data []byte, maxDepth int) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// Malformed JSON is reported by the regular body parsing
+			return nil
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("JSON nesting depth exceeds %d", maxDepth)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
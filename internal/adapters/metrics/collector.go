@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Collector implements ports.MetricsCollector with in-memory storage
+type Collector struct {
+	counters   map[string]*series
+	histograms map[string]*histogram
+	gauges     map[string]*series
+	mutex      sync.RWMutex
+}
+
+// series holds a single labelled value
+type series struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// histogram holds summary statistics for a labelled observation stream
+type histogram struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Count  int64             `json:"count"`
+	Sum    float64           `json:"sum"`
+	Min    float64           `json:"min"`
+	Max    float64           `json:"max"`
+}
+
+// Snapshot is a point-in-time copy of all collected metrics
+type Snapshot struct {
+	Counters   []series    `json:"counters"`
+	Histograms []histogram `json:"histograms"`
+	Gauges     []series    `json:"gauges"`
+}
+
+// NewCollector creates a new in-memory metrics collector
+func NewCollector() *Collector {
+	return &Collector{
+		counters:   make(map[string]*series),
+		histograms: make(map[string]*histogram),
+		gauges:     make(map[string]*series),
+	}
+}
+
+var _ ports.MetricsCollector = (*Collector)(nil)
+
+// IncrementCounter increments a counter by one
+func (c *Collector) IncrementCounter(name string, labels map[string]string) {
+	key := seriesKey(name, labels)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, exists := c.counters[key]
+	if !exists {
+		s = &series{Name: name, Labels: copyLabels(labels)}
+		c.counters[key] = s
+	}
+	s.Value++
+}
+
+// RecordHistogram records an observation
+func (c *Collector) RecordHistogram(name string, value float64, labels map[string]string) {
+	key := seriesKey(name, labels)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	h, exists := c.histograms[key]
+	if !exists {
+		h = &histogram{Name: name, Labels: copyLabels(labels), Min: value, Max: value}
+		c.histograms[key] = h
+	}
+	h.Count++
+	h.Sum += value
+	if value < h.Min {
+		h.Min = value
+	}
+	if value > h.Max {
+		h.Max = value
+	}
+}
+
+// SetGauge sets a gauge to the given value
+func (c *Collector) SetGauge(name string, value float64, labels map[string]string) {
+	key := seriesKey(name, labels)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, exists := c.gauges[key]
+	if !exists {
+		s = &series{Name: name, Labels: copyLabels(labels)}
+		c.gauges[key] = s
+	}
+	s.Value = value
+}
+
+// Snapshot returns a copy of all metrics sorted by name and labels
+func (c *Collector) Snapshot() Snapshot {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	snapshot := Snapshot{
+		Counters:   make([]series, 0, len(c.counters)),
+		Histograms: make([]histogram, 0, len(c.histograms)),
+		Gauges:     make([]series, 0, len(c.gauges)),
+	}
+	for _, key := range sortedKeys(c.counters) {
+		snapshot.Counters = append(snapshot.Counters, *c.counters[key])
+	}
+	for _, key := range sortedKeys(c.histograms) {
+		snapshot.Histograms = append(snapshot.Histograms, *c.histograms[key])
+	}
+	for _, key := range sortedKeys(c.gauges) {
+		snapshot.Gauges = append(snapshot.Gauges, *c.gauges[key])
+	}
+	return snapshot
+}
+
+// seriesKey builds a stable identifier from a metric name and its labels
+func seriesKey(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, label := range names {
+		sb.WriteString("|")
+		sb.WriteString(label)
+		sb.WriteString("=")
+		sb.WriteString(labels[label])
+	}
+	return sb.String()
+}
+
+// copyLabels copies a label map so callers can reuse theirs
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,294 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+const (
+	// defaultAttemptBudget is the share of requests that may trigger an extra attempt
+	defaultAttemptBudget = 0.1
+	// maxBudgetTokens caps how many extra attempts can be saved up during quiet periods
+	maxBudgetTokens = 10
+	// defaultHedgeDelay applies when a hedge policy sets neither a delay nor a percentile
+	defaultHedgeDelay = 100 * time.Millisecond
+	// latencyWindow is the number of recent latencies kept per route
+	latencyWindow = 256
+	// minLatencySamples is needed before a percentile replaces the fixed hedge delay
+	minLatencySamples = 20
+)
+
+// defaultRetryOn lists the statuses retried when a retry policy does not set its own
+var defaultRetryOn = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// roundTripWithPolicies runs hedged attempts and retries them while the shared budget allows
+func (st *serviceTransport) roundTripWithPolicies(req *http.Request, retry *ports.RetryPolicy, hedge *ports.HedgePolicy) (*http.Response, error) {
+	ratio := defaultAttemptBudget
+	if hedge != nil && hedge.Budget > 0 {
+		ratio = hedge.Budget
+	}
+	st.budget.deposit(ratio)
+
+	maxAttempts := 1
+	if retry != nil && retry.MaxAttempts > 1 {
+		maxAttempts = retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := st.hedgedAttempt(req, hedge)
+		if attempt >= maxAttempts || !shouldRetry(resp, err, retry) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if !st.budget.withdraw() {
+			st.metrics.IncrementCounter("gateway_attempt_budget_exhausted_total", map[string]string{"service": st.service})
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		st.metrics.IncrementCounter("gateway_retries_total", map[string]string{"service": st.service})
+		st.logger.Warn("Retrying upstream request", map[string]interface{}{
+			"service": st.service,
+			"attempt": attempt + 1,
+			"path":    req.URL.Path,
+		})
+
+		if retry.Backoff > 0 {
+			timer := time.NewTimer(retry.Backoff)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			}
+		}
+	}
+}
+
+// hedgedAttempt sends the request and, when it has not answered within the hedge
+// delay, a second copy to another endpoint. The first successful answer wins and
+// the other attempt is cancelled.
+func (st *serviceTransport) hedgedAttempt(req *http.Request, hedge *ports.HedgePolicy) (*http.Response, error) {
+	if hedge == nil {
+		return st.attempt(req, st.pickEndpoint(""))
+	}
+
+	type result struct {
+		resp   *http.Response
+		err    error
+		hedged bool
+		index  int
+	}
+	results := make(chan result, 2)
+	var cancels []context.CancelFunc
+
+	launch := func(endpoint string, hedged bool) {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := st.attempt(req.WithContext(ctx), endpoint)
+			results <- result{resp: resp, err: err, hedged: hedged, index: index}
+		}()
+	}
+
+	primary := st.pickEndpoint("")
+	launch(primary, false)
+	inFlight := 1
+
+	timer := time.NewTimer(st.hedgeDelay(ports.AttemptRouteFromContext(req.Context()), hedge))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if !st.budget.withdraw() {
+				st.metrics.IncrementCounter("gateway_attempt_budget_exhausted_total", map[string]string{"service": st.service})
+				continue
+			}
+			launch(st.pickEndpoint(primary), true)
+			inFlight++
+			st.metrics.IncrementCounter("gateway_hedges_total", map[string]string{"service": st.service, "outcome": "sent"})
+
+		case r := <-results:
+			inFlight--
+			if r.err != nil && inFlight > 0 {
+				// The other attempt may still succeed
+				cancels[r.index]()
+				continue
+			}
+
+			// Cancel the loser and discard its response if it still arrives
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+			if inFlight > 0 {
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if late := <-results; late.resp != nil {
+							late.resp.Body.Close()
+						}
+					}
+				}(inFlight)
+			}
+
+			if r.err != nil {
+				cancels[r.index]()
+				return nil, r.err
+			}
+			if r.hedged {
+				st.metrics.IncrementCounter("gateway_hedges_total", map[string]string{"service": st.service, "outcome": "won"})
+			}
+			// The winner's context lives until its body has been consumed
+			r.resp.Body = &releasingBody{ReadCloser: r.resp.Body, release: cancels[r.index]}
+			return r.resp, nil
+		}
+	}
+}
+
+// hedgeDelay returns the route's observed latency percentile, or the fixed delay until
+// enough samples exist
+func (st *serviceTransport) hedgeDelay(route string, hedge *ports.HedgePolicy) time.Duration {
+	if hedge.Percentile > 0 {
+		if delay, ok := st.latency.tracker(route).percentile(hedge.Percentile); ok {
+			return delay
+		}
+	}
+	if hedge.Delay > 0 {
+		return hedge.Delay
+	}
+	return defaultHedgeDelay
+}
+
+// shouldRetry reports whether an attempt failed in a way worth retrying
+func shouldRetry(resp *http.Response, err error, retry *ports.RetryPolicy) bool {
+	if retry == nil {
+		return false
+	}
+	if err != nil {
+		// Overload and TLS failures are not transient enough to retry
+		var bulkheadErr *domain.BulkheadError
+		var limitErr *domain.ConcurrencyLimitError
+		var tlsErr *domain.UpstreamTLSError
+		return !errors.As(err, &bulkheadErr) && !errors.As(err, &limitErr) && !errors.As(err, &tlsErr)
+	}
+
+	retryOn := retry.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = defaultRetryOn
+	}
+	for _, status := range retryOn {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isReplayable reports whether a request is idempotent and can be sent more than once
+func isReplayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody
+	default:
+		return false
+	}
+}
+
+// attemptBudget limits extra attempts to a share of the requests seen
+type attemptBudget struct {
+	tokens float64
+	mutex  sync.Mutex
+}
+
+// deposit adds the share of an attempt earned by a new request
+func (ab *attemptBudget) deposit(ratio float64) {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+	ab.tokens = math.Min(ab.tokens+ratio, maxBudgetTokens)
+}
+
+// withdraw takes one extra attempt from the budget if available
+func (ab *attemptBudget) withdraw() bool {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+	if ab.tokens < 1 {
+		return false
+	}
+	ab.tokens--
+	return true
+}
+
+// routeLatencies keeps a latency window per route, as the routes of one service can
+// differ widely in how long their calls take
+type routeLatencies struct {
+	trackers map[string]*latencyTracker
+	mutex    sync.Mutex
+}
+
+// tracker returns the latency window of a route, creating it on first use
+func (rl *routeLatencies) tracker(route string) *latencyTracker {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	if rl.trackers == nil {
+		rl.trackers = make(map[string]*latencyTracker)
+	}
+	tracker, exists := rl.trackers[route]
+	if !exists {
+		tracker = &latencyTracker{}
+		rl.trackers[route] = tracker
+	}
+	return tracker
+}
+
+// latencyTracker keeps a sliding window of recent response latencies
+type latencyTracker struct {
+	samples [latencyWindow]time.Duration
+	count   int
+	next    int
+	mutex   sync.Mutex
+}
+
+// record adds a latency to the window
+func (lt *latencyTracker) record(latency time.Duration) {
+	lt.mutex.Lock()
+	defer lt.mutex.Unlock()
+	lt.samples[lt.next] = latency
+	lt.next = (lt.next + 1) % latencyWindow
+	if lt.count < latencyWindow {
+		lt.count++
+	}
+}
+
+// percentile returns the given percentile of the window once it has enough samples
+func (lt *latencyTracker) percentile(p float64) (time.Duration, bool) {
+	lt.mutex.Lock()
+	if lt.count < minLatencySamples {
+		lt.mutex.Unlock()
+		return 0, false
+	}
+	sorted := make([]time.Duration, lt.count)
+	copy(sorted, lt.samples[:lt.count])
+	lt.mutex.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index], true
+}
@@ -0,0 +1,295 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/discovery"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// newTestTransport builds the transport of a service spread over static endpoints
+func newTestTransport(t *testing.T, service config.ServiceConfig, endpoints ...string) (*serviceTransport, *metrics.Collector) {
+	t.Helper()
+	log := logger.NewLogger("error", "json", "test")
+	service.Discovery = &config.DiscoveryConfig{Type: discovery.TypeStatic, Endpoints: endpoints}
+	services := map[string]config.ServiceConfig{"plants": service}
+
+	registry := discovery.NewRegistry(log)
+	if err := registry.Update(services); err != nil {
+		t.Fatal(err)
+	}
+	collector := metrics.NewCollector()
+	transports := NewTransportRegistry(registry, nil, collector, log)
+	t.Cleanup(transports.Stop)
+	if err := transports.Update(services); err != nil {
+		t.Fatal(err)
+	}
+	return transports.Transport("plants").(*serviceTransport), collector
+}
+
+// counterValue sums a counter across the label sets matching labels
+func counterValue(collector *metrics.Collector, name string, labels map[string]string) float64 {
+	var total float64
+	for _, counter := range collector.Snapshot().Counters {
+		if counter.Name != name {
+			continue
+		}
+		matches := true
+		for key, value := range labels {
+			if counter.Labels[key] != value {
+				matches = false
+			}
+		}
+		if matches {
+			total += counter.Value
+		}
+	}
+	return total
+}
+
+// get sends a GET through the transport with the given attempt policies
+func get(t *testing.T, transport http.RoundTripper, retry *ports.RetryPolicy, hedge *ports.HedgePolicy) (*http.Response, error) {
+	t.Helper()
+	ctx := ports.WithAttemptPolicies(context.Background(), "GET /api/v1/plants", retry, hedge)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://plants:8080/api/v1/plants", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transport.RoundTrip(req)
+}
+
+func TestAttemptBudget(t *testing.T) {
+	budget := &attemptBudget{}
+	if budget.withdraw() {
+		t.Fatal("withdraw() succeeded on an empty budget")
+	}
+
+	// Four requests at a 25% ratio earn one extra attempt
+	for i := 0; i < 4; i++ {
+		budget.deposit(0.25)
+	}
+	if !budget.withdraw() {
+		t.Error("withdraw() refused after four deposits of 0.25")
+	}
+	if budget.withdraw() {
+		t.Error("withdraw() allowed a second attempt")
+	}
+
+	// Quiet periods save up no more than the cap
+	for i := 0; i < 1000; i++ {
+		budget.deposit(1)
+	}
+	withdrawn := 0
+	for budget.withdraw() {
+		withdrawn++
+	}
+	if withdrawn != maxBudgetTokens {
+		t.Errorf("withdrawn = %d, want %d", withdrawn, maxBudgetTokens)
+	}
+}
+
+func TestLatencyTrackerPercentile(t *testing.T) {
+	tracker := &latencyTracker{}
+	for i := 1; i < minLatencySamples; i++ {
+		tracker.record(time.Duration(i) * time.Millisecond)
+	}
+	if _, ok := tracker.percentile(90); ok {
+		t.Fatalf("percentile() answered with %d samples", minLatencySamples-1)
+	}
+
+	tracker = &latencyTracker{}
+	for i := 1; i <= 100; i++ {
+		tracker.record(time.Duration(i) * time.Millisecond)
+	}
+	tests := []struct {
+		percentile float64
+		want       time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99.5, 100 * time.Millisecond},
+		{0, time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got, ok := tracker.percentile(tt.percentile); !ok || got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.percentile, got, tt.want)
+		}
+	}
+
+	// Old samples leave the window
+	for i := 0; i < latencyWindow; i++ {
+		tracker.record(time.Second)
+	}
+	if got, _ := tracker.percentile(50); got != time.Second {
+		t.Errorf("percentile(50) = %v after the window moved on, want 1s", got)
+	}
+}
+
+func TestHedgeDelay(t *testing.T) {
+	st := &serviceTransport{latency: &routeLatencies{}}
+	for i := 1; i <= minLatencySamples; i++ {
+		st.latency.tracker("GET /fast").record(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		name  string
+		route string
+		hedge *ports.HedgePolicy
+		want  time.Duration
+	}{
+		{"fixed delay", "GET /fast", &ports.HedgePolicy{Delay: 30 * time.Millisecond}, 30 * time.Millisecond},
+		{"default delay", "GET /fast", &ports.HedgePolicy{}, defaultHedgeDelay},
+		{"observed percentile", "GET /fast", &ports.HedgePolicy{Percentile: 50, Delay: time.Second}, 10 * time.Millisecond},
+		{"too few samples for the route", "GET /slow", &ports.HedgePolicy{Percentile: 50, Delay: time.Second}, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := st.hedgeDelay(tt.route, tt.hedge); got != tt.want {
+				t.Errorf("hedgeDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	policy := &ports.RetryPolicy{MaxAttempts: 3}
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		retry  *ports.RetryPolicy
+		want   bool
+	}{
+		{"no policy", http.StatusBadGateway, nil, nil, false},
+		{"default status", http.StatusServiceUnavailable, nil, policy, true},
+		{"success", http.StatusOK, nil, policy, false},
+		{"client error", http.StatusNotFound, nil, policy, false},
+		{"configured status", http.StatusTooManyRequests, nil, &ports.RetryPolicy{MaxAttempts: 3, RetryOn: []int{429}}, true},
+		{"connection error", 0, errors.New("connection refused"), policy, true},
+		{"bulkhead full", 0, &domain.BulkheadError{Scope: "service", Name: "plants", Reason: "queue_full"}, policy, false},
+		{"concurrency limited", 0, &domain.ConcurrencyLimitError{Service: "plants", Limit: 5}, policy, false},
+		{"TLS failure", 0, &domain.UpstreamTLSError{Service: "plants", Err: errors.New("bad certificate")}, policy, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			if got := shouldRetry(resp, tt.err, tt.retry); got != tt.want {
+				t.Errorf("shouldRetry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetriesWithinBudget(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	transport, collector := newTestTransport(t, config.ServiceConfig{URL: "http://plants:8080"}, server.URL)
+	retry := &ports.RetryPolicy{MaxAttempts: 3}
+	resp, err := get(t, transport, retry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || atomic.LoadInt64(&calls) != 2 {
+		t.Fatalf("status %d after %d calls, want 200 after 2", resp.StatusCode, atomic.LoadInt64(&calls))
+	}
+
+	// With the budget spent the failed answer is returned as is
+	transport.budget = &attemptBudget{}
+	atomic.StoreInt64(&calls, 0)
+	resp, err = get(t, transport, retry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt64(&calls) != 1 {
+		t.Errorf("status %d after %d calls, want 503 after 1", resp.StatusCode, atomic.LoadInt64(&calls))
+	}
+	if got := counterValue(collector, "gateway_attempt_budget_exhausted_total", nil); got != 1 {
+		t.Errorf("budget exhausted = %v, want 1", got)
+	}
+}
+
+func TestHedgedAttempt(t *testing.T) {
+	slowCancelled := make(chan struct{}, 1)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			slowCancelled <- struct{}{}
+		case <-time.After(2 * time.Second):
+			io.WriteString(w, "slow")
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "fast")
+	}))
+	defer fast.Close()
+
+	// Round robin sends the first attempt to the slow instance
+	transport, collector := newTestTransport(t, config.ServiceConfig{URL: "http://plants:8080"}, slow.URL, fast.URL)
+	resp, err := get(t, transport, nil, &ports.HedgePolicy{Delay: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "fast" {
+		t.Errorf("body = %q, want the hedged answer", body)
+	}
+	if got := counterValue(collector, "gateway_hedges_total", map[string]string{"outcome": "won"}); got != 1 {
+		t.Errorf("hedges won = %v, want 1", got)
+	}
+	select {
+	case <-slowCancelled:
+	case <-time.After(time.Second):
+		t.Error("the slow attempt was not cancelled")
+	}
+}
+
+func TestHedgeSkippedWithoutBudget(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	transport, collector := newTestTransport(t, config.ServiceConfig{URL: "http://plants:8080"}, server.URL)
+	transport.budget = &attemptBudget{}
+	// A budget share below one request's worth never pays for a hedge
+	resp, err := get(t, transport, nil, &ports.HedgePolicy{Delay: time.Millisecond, Budget: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Errorf("calls = %d, want only the first attempt", got)
+	}
+	if got := counterValue(collector, "gateway_hedges_total", nil); got != 0 {
+		t.Errorf("hedges = %v, want none", got)
+	}
+	if got := counterValue(collector, "gateway_attempt_budget_exhausted_total", nil); got != 1 {
+		t.Errorf("budget exhausted = %v, want 1", got)
+	}
+}
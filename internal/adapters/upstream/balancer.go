@@ -0,0 +1,41 @@
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// roundRobin spreads requests evenly across the endpoints of a service
+type roundRobin struct {
+	counter uint64
+}
+
+// pick returns the next endpoint in turn
+func (rr *roundRobin) pick(endpoints []string) string {
+	index := atomic.AddUint64(&rr.counter, 1) - 1
+	return endpoints[index%uint64(len(endpoints))]
+}
+
+// withEndpoint returns a copy of the request sent to the given endpoint.
+// Endpoints of a service share the base path of the configured service URL,
+// so only the scheme and address are replaced; the Host header keeps naming the
+// service so name-based virtual hosting still works with discovered IPs.
+func withEndpoint(req *http.Request, endpoint string) (*http.Request, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if target.Scheme == req.URL.Scheme && target.Host == req.URL.Host {
+		return req, nil
+	}
+
+	routed := req.Clone(req.Context())
+	routed.URL.Scheme = target.Scheme
+	routed.URL.Host = target.Host
+	if routed.Host == "" {
+		routed.Host = req.URL.Host
+	}
+	return routed, nil
+}
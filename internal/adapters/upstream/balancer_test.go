@@ -0,0 +1,100 @@
+package upstream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/discovery"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+)
+
+func TestWithEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		host     string // explicit Host header, empty to leave it unset
+		endpoint string
+		wantURL  string
+		wantHost string
+	}{
+		{"discovered IP keeps the service name", "http://plants:8080/api/v1/plants?page=2", "", "http://10.0.0.7:8080", "http://10.0.0.7:8080/api/v1/plants?page=2", "plants:8080"},
+		{"explicit host header is kept", "http://plants:8080/api/v1/plants", "plants.example.com", "http://10.0.0.7:8080", "http://10.0.0.7:8080/api/v1/plants", "plants.example.com"},
+		{"scheme follows the endpoint", "http://plants/api", "", "https://10.0.0.7:8443", "https://10.0.0.7:8443/api", "plants"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &http.Request{Method: "GET", URL: parsed, Host: tt.host, Header: http.Header{}}
+			routed, err := withEndpoint(req, tt.endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if routed.URL.String() != tt.wantURL || routed.Host != tt.wantHost {
+				t.Errorf("withEndpoint() = %s host %s, want %s host %s", routed.URL, routed.Host, tt.wantURL, tt.wantHost)
+			}
+			if req.URL.String() != tt.url {
+				t.Errorf("original request changed to %s", req.URL)
+			}
+		})
+	}
+}
+
+func TestServiceTransportRoutesToDiscoveredEndpoints(t *testing.T) {
+	type received struct{ host, path, instance string }
+	var mutex sync.Mutex
+	var requests []received
+	instance := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			requests = append(requests, received{r.Host, r.URL.Path, name})
+			mutex.Unlock()
+		}))
+	}
+	first, second := instance("first"), instance("second")
+	defer first.Close()
+	defer second.Close()
+
+	log := logger.NewLogger("error", "json", "test")
+	services := map[string]config.ServiceConfig{
+		"plants": {
+			URL:       "http://plants.internal:8080/base",
+			Discovery: &config.DiscoveryConfig{Type: discovery.TypeStatic, Endpoints: []string{first.URL, second.URL}},
+		},
+	}
+	registry := discovery.NewRegistry(log)
+	if err := registry.Update(services); err != nil {
+		t.Fatal(err)
+	}
+	transports := NewTransportRegistry(registry, nil, metrics.NewCollector(), log)
+	defer transports.Stop()
+	if err := transports.Update(services); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: transports.Transport("plants")}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(services["plants"].URL + "/api/v1/plants")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(requests) != 2 || requests[0].instance == requests[1].instance {
+		t.Fatalf("requests = %+v, want one on each instance", requests)
+	}
+	for _, r := range requests {
+		if r.host != "plants.internal:8080" || r.path != "/base/api/v1/plants" {
+			t.Errorf("%s instance got host %s path %s, want host plants.internal:8080 path /base/api/v1/plants", r.instance, r.host, r.path)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package upstream
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
+)
+
+// FaultHeader marks responses produced by an injected abort
+const FaultHeader = "X-Gateway-Fault"
+
+// send calls the upstream, applying the faults selected for this call. Added
+// latency comes first, then an abort or connection reset replaces the call, and a
+// truncation cuts the body of the real response. injected reports whether the
+// outcome or timing of the call was made up, so it says nothing about the upstream.
+func (st *serviceTransport) send(req *http.Request) (resp *http.Response, injected bool, err error) {
+	faults := st.selectFaults(req)
+	if len(faults) == 0 {
+		resp, err = st.base.RoundTrip(req)
+		return resp, false, err
+	}
+
+	for _, fault := range faults {
+		st.logger.Debug("Injecting fault", map[string]interface{}{
+			"service": st.service,
+			"type":    fault.Type,
+			"path":    req.URL.Path,
+		})
+	}
+
+	for _, fault := range faults {
+		if fault.Type != services.FaultLatency {
+			continue
+		}
+		timer := time.NewTimer(fault.Delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, true, req.Context().Err()
+		}
+		injected = true
+	}
+
+	var truncate *ports.FaultRule
+	for i, fault := range faults {
+		switch fault.Type {
+		case services.FaultAbort:
+			return abortedResponse(req, fault.Status), true, nil
+		case services.FaultReset:
+			return nil, true, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+		case services.FaultTruncate:
+			truncate = &faults[i]
+		}
+	}
+
+	resp, err = st.base.RoundTrip(req)
+	if err == nil && truncate != nil {
+		resp.Body = &truncatedBody{ReadCloser: resp.Body, remaining: truncate.Bytes}
+	}
+	return resp, injected, err
+}
+
+// selectFaults returns the route and service faults that apply to this call. The
+// route's rules and the client's headers travel in the request context, so faults
+// also reach the sub-calls of orchestrated routes.
+func (st *serviceTransport) selectFaults(req *http.Request) []ports.FaultRule {
+	if st.injector == nil {
+		return nil
+	}
+
+	scope, found := ports.FaultScopeFromContext(req.Context())
+	headers := scope.Headers
+	if !found {
+		headers = make(map[string]string, len(req.Header))
+		for name := range req.Header {
+			headers[strings.ToLower(name)] = req.Header.Get(name)
+		}
+	}
+
+	faults := st.injector.Select(services.FaultRouteTarget(scope.Route), scope.Rules, headers)
+	return append(faults, st.injector.Select(services.FaultServiceTarget(st.service), st.faults, headers)...)
+}
+
+// convertFault converts a configured fault to a fault rule
+func convertFault(fault config.FaultConfig) ports.FaultRule {
+	return ports.FaultRule{
+		Type:       fault.Type,
+		Delay:      fault.Delay,
+		Status:     fault.Status,
+		Bytes:      fault.Bytes,
+		Percentage: fault.Percentage,
+		Header:     fault.Header,
+	}
+}
+
+// abortedResponse answers in place of the upstream with the injected status
+func abortedResponse(req *http.Request, status int) *http.Response {
+	body := []byte(`{"error":"Injected fault","code":"FAULT_INJECTED"}`)
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}, FaultHeader: {services.FaultAbort}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncatedBody passes through a number of bytes and then fails as if the
+// connection had dropped mid-body
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads until the cut and then reports an unexpected end of the body
+func (tb *truncatedBody) Read(p []byte) (int, error) {
+	if tb.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > tb.remaining {
+		p = p[:tb.remaining]
+	}
+	n, err := tb.ReadCloser.Read(p)
+	tb.remaining -= int64(n)
+	return n, err
+}
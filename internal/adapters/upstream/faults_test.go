@@ -0,0 +1,116 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
+)
+
+func TestSendInjectsFaults(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		io.WriteString(w, "0123456789")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		rule      ports.FaultRule
+		wantCalls int64
+		check     func(t *testing.T, resp *http.Response, err error)
+	}{
+		{"abort", ports.FaultRule{Type: services.FaultAbort, Status: http.StatusServiceUnavailable, Percentage: 100}, 0, func(t *testing.T, resp *http.Response, err error) {
+			if err != nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get(FaultHeader) != services.FaultAbort {
+				t.Errorf("response = %v, %v, want an injected 503", resp, err)
+			}
+		}},
+		{"reset", ports.FaultRule{Type: services.FaultReset, Percentage: 100}, 0, func(t *testing.T, resp *http.Response, err error) {
+			if !errors.Is(err, syscall.ECONNRESET) {
+				t.Errorf("error = %v, want a connection reset", err)
+			}
+		}},
+		{"truncate", ports.FaultRule{Type: services.FaultTruncate, Bytes: 4, Percentage: 100}, 1, func(t *testing.T, resp *http.Response, err error) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if string(body) != "0123" || !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("body = %q, %v, want 4 bytes and an unexpected EOF", body, err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, _ := newTestTransport(t, config.ServiceConfig{URL: "http://plants:8080"}, server.URL)
+			transport.injector = services.NewFaultInjector(true, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+			transport.faults = []ports.FaultRule{tt.rule}
+			atomic.StoreInt64(&calls, 0)
+
+			resp, err := get(t, transport, nil, nil)
+			tt.check(t, resp, err)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if got := atomic.LoadInt64(&calls); got != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSendRouteFaultsFromContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport, _ := newTestTransport(t, config.ServiceConfig{URL: "http://plants:8080"}, server.URL)
+	injector := services.NewFaultInjector(true, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+	transport.injector = injector
+	delay := ports.FaultRule{Type: services.FaultLatency, Delay: 50 * time.Millisecond, Percentage: 100, Header: "X-Chaos"}
+
+	send := func(headers map[string]string, timeout time.Duration) (time.Duration, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		ctx = ports.WithFaultScope(ctx, ports.FaultScope{Route: "GET /api/v1/plants", Rules: []ports.FaultRule{delay}, Headers: headers})
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://plants:8080/api/v1/plants", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		resp, err := transport.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return time.Since(start), err
+	}
+
+	// The client's headers travel with the scope, so sub-calls see them too
+	if elapsed, err := send(map[string]string{"x-chaos": "on"}, time.Second); err != nil || elapsed < 50*time.Millisecond {
+		t.Errorf("opted-in call took %v, %v, want the added delay", elapsed, err)
+	}
+	if elapsed, err := send(nil, time.Second); err != nil || elapsed >= 50*time.Millisecond {
+		t.Errorf("call without the header took %v, %v, want no delay", elapsed, err)
+	}
+	// A caller giving up ends the injected delay
+	if _, err := send(map[string]string{"x-chaos": "on"}, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want the context deadline", err)
+	}
+
+	injector.SetEnabled(services.FaultRouteTarget("GET /api/v1/plants"), false)
+	if elapsed, err := send(map[string]string{"x-chaos": "on"}, time.Second); err != nil || elapsed >= 50*time.Millisecond {
+		t.Errorf("call on a switched-off route took %v, %v, want no delay", elapsed, err)
+	}
+}
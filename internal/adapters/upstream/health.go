@@ -0,0 +1,364 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+const (
+	defaultHealthInterval           = 10 * time.Second
+	defaultHealthTimeout            = 2 * time.Second
+	defaultHealthyThreshold         = 2
+	defaultUnhealthyThreshold       = 3
+	healthProbeBodyLimit      int64 = 4 << 10
+)
+
+// HealthChecker implements ports.HealthChecker by probing every instance of the
+// services that configure a health check. Instances that fail their probes are
+// left out of load balancing until they pass again.
+type HealthChecker struct {
+	services  map[string]*serviceHealth
+	discovery ports.ServiceDiscovery
+	mutex     sync.RWMutex
+	metrics   ports.MetricsCollector
+	logger    ports.Logger
+}
+
+// serviceHealth tracks the health of one service and each of its instances
+type serviceHealth struct {
+	service            *domain.Service
+	path               string
+	interval           time.Duration
+	healthyThreshold   int
+	unhealthyThreshold int
+	transport          http.RoundTripper
+	endpoints          map[string]*endpointHealth
+	mutex              sync.RWMutex
+	done               chan struct{}
+	once               sync.Once
+}
+
+// endpointHealth is the probe history of one service instance
+type endpointHealth struct {
+	status      domain.ServiceStatus
+	successes   int
+	failures    int
+	lastChecked time.Time
+	lastError   string
+}
+
+// newHealthChecker creates a health checker without any services
+func newHealthChecker(discovery ports.ServiceDiscovery, metrics ports.MetricsCollector, logger ports.Logger) *HealthChecker {
+	return &HealthChecker{
+		services:  make(map[string]*serviceHealth),
+		discovery: discovery,
+		metrics:   metrics,
+		logger:    logger,
+	}
+}
+
+// update starts checking the services that configure a health check, probing
+// through their transports so TLS settings apply. Known instance states are kept.
+func (hc *HealthChecker) update(services map[string]config.ServiceConfig, transports map[string]*serviceTransport) {
+	checked := make(map[string]*serviceHealth)
+	hc.mutex.RLock()
+	previous := hc.services
+	hc.mutex.RUnlock()
+
+	for name, service := range services {
+		if service.HealthCheck == nil || service.HealthCheck.Path == "" {
+			continue
+		}
+		sh := &serviceHealth{
+			service: &domain.Service{
+				Name:        name,
+				URL:         service.URL,
+				Status:      domain.ServiceStatusUnknown,
+				Timeout:     service.HealthCheck.Timeout,
+				HealthCheck: service.HealthCheck.Path,
+			},
+			path:               service.HealthCheck.Path,
+			interval:           service.HealthCheck.Interval,
+			healthyThreshold:   orDefault(service.HealthCheck.HealthyThreshold, defaultHealthyThreshold),
+			unhealthyThreshold: orDefault(service.HealthCheck.UnhealthyThreshold, defaultUnhealthyThreshold),
+			transport:          transports[name].base,
+			endpoints:          make(map[string]*endpointHealth),
+			done:               make(chan struct{}),
+		}
+		if sh.interval <= 0 {
+			sh.interval = defaultHealthInterval
+		}
+		if sh.service.Timeout <= 0 {
+			sh.service.Timeout = defaultHealthTimeout
+		}
+		if old, exists := previous[name]; exists {
+			old.mutex.RLock()
+			for endpoint, state := range old.endpoints {
+				copied := *state
+				sh.endpoints[endpoint] = &copied
+			}
+			sh.service.Status, sh.service.LastChecked = old.service.Status, old.service.LastChecked
+			old.mutex.RUnlock()
+		}
+		checked[name] = sh
+	}
+
+	hc.mutex.Lock()
+	hc.services = checked
+	hc.mutex.Unlock()
+
+	for _, sh := range previous {
+		sh.stop()
+	}
+	for name, sh := range checked {
+		go hc.run(name, sh)
+	}
+}
+
+// stop stops all background checks
+func (hc *HealthChecker) stop() {
+	hc.mutex.RLock()
+	defer hc.mutex.RUnlock()
+	for _, sh := range hc.services {
+		sh.stop()
+	}
+}
+
+// CheckHealth returns the result of the latest background checks of a service
+func (hc *HealthChecker) CheckHealth(ctx context.Context, serviceName string) (ports.HealthStatus, error) {
+	hc.mutex.RLock()
+	sh, exists := hc.services[serviceName]
+	hc.mutex.RUnlock()
+	if !exists {
+		return ports.HealthStatus{
+			Status:    string(domain.ServiceStatusUnknown),
+			Message:   "health check not configured",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	}
+	return sh.status(), nil
+}
+
+// CheckAllServices returns the result of the latest background checks of every checked service
+func (hc *HealthChecker) CheckAllServices(ctx context.Context) (map[string]ports.HealthStatus, error) {
+	hc.mutex.RLock()
+	defer hc.mutex.RUnlock()
+	statuses := make(map[string]ports.HealthStatus, len(hc.services))
+	for name, sh := range hc.services {
+		statuses[name] = sh.status()
+	}
+	return statuses, nil
+}
+
+// isAvailable reports whether an instance may receive traffic. Instances that
+// have not been probed yet, or belong to unchecked services, are available.
+func (hc *HealthChecker) isAvailable(serviceName, endpoint string) bool {
+	hc.mutex.RLock()
+	sh, exists := hc.services[serviceName]
+	hc.mutex.RUnlock()
+	if !exists {
+		return true
+	}
+	sh.mutex.RLock()
+	defer sh.mutex.RUnlock()
+	state, probed := sh.endpoints[endpoint]
+	return !probed || state.status != domain.ServiceStatusUnhealthy
+}
+
+// run probes the service right away and then whenever a check is due
+func (hc *HealthChecker) run(name string, sh *serviceHealth) {
+	hc.probeAll(name, sh)
+
+	ticker := time.NewTicker(sh.interval / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sh.done:
+			return
+		case <-ticker.C:
+			sh.mutex.RLock()
+			due := sh.service.NeedsHealthCheck(sh.interval)
+			sh.mutex.RUnlock()
+			if due {
+				hc.probeAll(name, sh)
+			}
+		}
+	}
+}
+
+// probeAll probes every current instance of the service in parallel
+func (hc *HealthChecker) probeAll(name string, sh *serviceHealth) {
+	endpoints := hc.discovery.Endpoints(name)
+	if len(endpoints) == 0 {
+		endpoints = []string{sh.service.URL}
+	}
+
+	var wg sync.WaitGroup
+	results := make([]error, len(endpoints))
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			results[i] = sh.probe(endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	sh.mutex.Lock()
+	current := make(map[string]*endpointHealth, len(endpoints))
+	for i, endpoint := range endpoints {
+		state, exists := sh.endpoints[endpoint]
+		if !exists {
+			state = &endpointHealth{status: domain.ServiceStatusUnknown}
+		}
+		previous := state.status
+		sh.record(state, results[i], now)
+		current[endpoint] = state
+
+		if state.status != previous {
+			hc.logTransition(name, endpoint, state)
+		}
+		healthy := 0.0
+		if state.status == domain.ServiceStatusHealthy {
+			healthy = 1
+		}
+		hc.metrics.SetGauge("gateway_upstream_endpoint_healthy", healthy, map[string]string{"service": name, "endpoint": endpoint})
+	}
+	sh.endpoints = current
+	sh.service.Status = aggregateStatus(current)
+	sh.service.LastChecked = now
+	sh.mutex.Unlock()
+}
+
+// probe sends one health request to an instance
+func (sh *serviceHealth) probe(endpoint string) error {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	target.Path = sh.path
+	target.RawQuery = ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), sh.service.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := sh.transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, healthProbeBodyLimit))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("health check returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// record applies a probe result to an instance, changing its status once the
+// matching threshold of consecutive results is reached; callers hold the mutex
+func (sh *serviceHealth) record(state *endpointHealth, err error, now time.Time) {
+	state.lastChecked = now
+	if err == nil {
+		state.successes++
+		state.failures = 0
+		state.lastError = ""
+		if state.successes >= sh.healthyThreshold {
+			state.status = domain.ServiceStatusHealthy
+		}
+		return
+	}
+	state.failures++
+	state.successes = 0
+	state.lastError = err.Error()
+	if state.failures >= sh.unhealthyThreshold {
+		state.status = domain.ServiceStatusUnhealthy
+	}
+}
+
+// status reports the service and instance states
+func (sh *serviceHealth) status() ports.HealthStatus {
+	sh.mutex.RLock()
+	defer sh.mutex.RUnlock()
+
+	endpoints := make([]string, 0, len(sh.endpoints))
+	for endpoint := range sh.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	instances := make([]map[string]interface{}, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		state := sh.endpoints[endpoint]
+		instance := map[string]interface{}{
+			"endpoint":     endpoint,
+			"status":       string(state.status),
+			"last_checked": state.lastChecked.UTC().Format(time.RFC3339),
+		}
+		if state.lastError != "" {
+			instance["error"] = state.lastError
+		}
+		instances = append(instances, instance)
+	}
+
+	status := ports.HealthStatus{
+		Status:   string(sh.service.Status),
+		Metadata: map[string]interface{}{"instances": instances, "path": sh.path},
+	}
+	if !sh.service.LastChecked.IsZero() {
+		status.Timestamp = sh.service.LastChecked.UTC().Format(time.RFC3339)
+	}
+	return status
+}
+
+// stop stops the background checks of the service
+func (sh *serviceHealth) stop() {
+	sh.once.Do(func() { close(sh.done) })
+}
+
+// logTransition reports an instance changing status
+func (hc *HealthChecker) logTransition(service, endpoint string, state *endpointHealth) {
+	fields := map[string]interface{}{
+		"service":  service,
+		"endpoint": endpoint,
+		"status":   string(state.status),
+	}
+	if state.status == domain.ServiceStatusUnhealthy {
+		fields["error"] = state.lastError
+		hc.logger.Warn("⚠️ Upstream instance marked unhealthy", fields)
+		return
+	}
+	hc.logger.Info("💚 Upstream instance health changed", fields)
+}
+
+// aggregateStatus is healthy while any instance is healthy and unhealthy once all are
+func aggregateStatus(endpoints map[string]*endpointHealth) domain.ServiceStatus {
+	unhealthy := 0
+	for _, state := range endpoints {
+		if state.status == domain.ServiceStatusHealthy {
+			return domain.ServiceStatusHealthy
+		}
+		if state.status == domain.ServiceStatusUnhealthy {
+			unhealthy++
+		}
+	}
+	if len(endpoints) > 0 && unhealthy == len(endpoints) {
+		return domain.ServiceStatusUnhealthy
+	}
+	return domain.ServiceStatusUnknown
+}
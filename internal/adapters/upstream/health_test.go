@@ -0,0 +1,138 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+)
+
+func TestHealthThresholds(t *testing.T) {
+	failed := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		results []error
+		want    domain.ServiceStatus
+	}{
+		{"first success is not enough", []error{nil}, domain.ServiceStatusUnknown},
+		{"healthy after two successes", []error{nil, nil}, domain.ServiceStatusHealthy},
+		{"one failure keeps a healthy instance", []error{nil, nil, failed}, domain.ServiceStatusHealthy},
+		{"unhealthy after three failures", []error{nil, nil, failed, failed, failed}, domain.ServiceStatusUnhealthy},
+		{"a success resets the failures", []error{failed, failed, nil, failed, failed}, domain.ServiceStatusUnknown},
+		{"recovers after two successes", []error{failed, failed, failed, nil, nil}, domain.ServiceStatusHealthy},
+	}
+
+	sh := &serviceHealth{healthyThreshold: defaultHealthyThreshold, unhealthyThreshold: defaultUnhealthyThreshold}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &endpointHealth{status: domain.ServiceStatusUnknown}
+			for _, result := range tt.results {
+				sh.record(state, result, time.Now())
+			}
+			if state.status != tt.want {
+				t.Errorf("status = %s, want %s", state.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	healthy := &endpointHealth{status: domain.ServiceStatusHealthy}
+	unhealthy := &endpointHealth{status: domain.ServiceStatusUnhealthy}
+	unknown := &endpointHealth{status: domain.ServiceStatusUnknown}
+
+	tests := []struct {
+		name      string
+		endpoints map[string]*endpointHealth
+		want      domain.ServiceStatus
+	}{
+		{"no instances", map[string]*endpointHealth{}, domain.ServiceStatusUnknown},
+		{"any healthy instance", map[string]*endpointHealth{"a": unhealthy, "b": healthy}, domain.ServiceStatusHealthy},
+		{"all unhealthy", map[string]*endpointHealth{"a": unhealthy, "b": unhealthy}, domain.ServiceStatusUnhealthy},
+		{"some not probed yet", map[string]*endpointHealth{"a": unhealthy, "b": unknown}, domain.ServiceStatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregateStatus(tt.endpoints); got != tt.want {
+				t.Errorf("aggregateStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerSkipsFailingInstances(t *testing.T) {
+	var wrongPath int64
+	instance := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/health" {
+				atomic.AddInt64(&wrongPath, 1)
+			}
+			w.WriteHeader(status)
+		}))
+	}
+	good, bad := instance(http.StatusOK), instance(http.StatusInternalServerError)
+	defer good.Close()
+	defer bad.Close()
+
+	transport, _ := newTestTransport(t, config.ServiceConfig{
+		URL: "http://plants:8080",
+		HealthCheck: &config.HealthCheckConfig{
+			Path:               "/health",
+			Interval:           20 * time.Millisecond,
+			HealthyThreshold:   1,
+			UnhealthyThreshold: 1,
+		},
+	}, good.URL, bad.URL)
+	checker := transport.health
+
+	deadline := time.Now().Add(2 * time.Second)
+	for checker.isAvailable("plants", bad.URL) {
+		if time.Now().After(deadline) {
+			t.Fatal("the failing instance was never marked unhealthy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	status, err := checker.CheckHealth(context.Background(), "plants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != string(domain.ServiceStatusHealthy) {
+		t.Errorf("service status = %s, want healthy while one instance is", status.Status)
+	}
+	instances := status.Metadata["instances"].([]map[string]interface{})
+	if len(instances) != 2 {
+		t.Fatalf("instances = %v, want both", instances)
+	}
+	for _, instance := range instances {
+		want := string(domain.ServiceStatusHealthy)
+		if instance["endpoint"] == bad.URL {
+			want = string(domain.ServiceStatusUnhealthy)
+		}
+		if instance["status"] != want {
+			t.Errorf("%s status = %v, want %s", instance["endpoint"], instance["status"], want)
+		}
+	}
+
+	// Traffic goes only to the healthy instance
+	for i := 0; i < 4; i++ {
+		if endpoint := transport.pickEndpoint(""); endpoint != good.URL {
+			t.Errorf("pickEndpoint() = %s, want the healthy instance", endpoint)
+		}
+	}
+	if atomic.LoadInt64(&wrongPath) != 0 {
+		t.Error("probes did not use the configured health path")
+	}
+
+	// Services without a health check are reported but never held back
+	status, _ = checker.CheckHealth(context.Background(), "reports")
+	if status.Status != string(domain.ServiceStatusUnknown) || !checker.isAvailable("reports", "http://10.0.0.1") {
+		t.Errorf("unchecked service = %+v, want unknown and available", status)
+	}
+}
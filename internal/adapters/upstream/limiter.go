@@ -0,0 +1,209 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Adaptive limit algorithms
+const (
+	LimitGradient = "gradient"
+	LimitAIMD     = "aimd"
+)
+
+const (
+	defaultInitialLimit = 10
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 500
+	defaultTolerance    = 1.5
+	defaultBackoffRatio = 0.9
+	// shortRTTWeight smooths the recent latency
+	shortRTTWeight = 0.1
+	// baselineWindow is how long the no-load baseline takes to follow a lasting latency increase
+	baselineWindow = time.Minute
+	// limitSmoothing is how far a limit's worth of samples moves the limit towards its target
+	limitSmoothing = 0.2
+)
+
+// adaptiveLimiter sheds requests above a concurrency limit that follows the
+// service's latency. The limit grows while latency stays near its long-term
+// baseline and the limit is in use, and shrinks when latency rises or the
+// service reports overload.
+type adaptiveLimiter struct {
+	service      string
+	algorithm    string
+	limit        float64
+	minLimit     float64
+	maxLimit     float64
+	tolerance    float64
+	backoffRatio float64
+	inFlight     int
+	shortRTT     float64
+	longRTT      float64
+	lastSample   time.Time
+	lastBackoff  time.Time
+	mutex        sync.Mutex
+	metrics      ports.MetricsCollector
+}
+
+// newAdaptiveLimiter creates a limiter from the service configuration
+func newAdaptiveLimiter(service string, cfg config.AdaptiveLimitConfig, metrics ports.MetricsCollector) (*adaptiveLimiter, error) {
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = LimitGradient
+	}
+	if algorithm != LimitGradient && algorithm != LimitAIMD {
+		return nil, fmt.Errorf("unknown adaptive limit algorithm %q", algorithm)
+	}
+
+	al := &adaptiveLimiter{
+		service:      service,
+		algorithm:    algorithm,
+		limit:        float64(orDefault(cfg.InitialLimit, defaultInitialLimit)),
+		minLimit:     float64(orDefault(cfg.MinLimit, defaultMinLimit)),
+		maxLimit:     float64(orDefault(cfg.MaxLimit, defaultMaxLimit)),
+		tolerance:    cfg.Tolerance,
+		backoffRatio: cfg.BackoffRatio,
+		metrics:      metrics,
+	}
+	if al.tolerance <= 1 {
+		al.tolerance = defaultTolerance
+	}
+	if al.backoffRatio <= 0 || al.backoffRatio >= 1 {
+		al.backoffRatio = defaultBackoffRatio
+	}
+	if al.minLimit > al.maxLimit {
+		return nil, fmt.Errorf("adaptive limit min_limit %d exceeds max_limit %d", cfg.MinLimit, cfg.MaxLimit)
+	}
+	al.limit = math.Max(al.minLimit, math.Min(al.limit, al.maxLimit))
+	al.publish()
+	return al, nil
+}
+
+// acquire takes a slot, or fails immediately when the limit is reached
+func (al *adaptiveLimiter) acquire() (*limitToken, error) {
+	al.mutex.Lock()
+	if al.inFlight >= int(al.limit) {
+		limit := int(al.limit)
+		al.mutex.Unlock()
+		al.metrics.IncrementCounter("gateway_adaptive_limit_rejections_total", map[string]string{"service": al.service})
+		return nil, &domain.ConcurrencyLimitError{Service: al.service, Limit: limit}
+	}
+	al.inFlight++
+	inFlight := al.inFlight
+	al.mutex.Unlock()
+
+	al.metrics.SetGauge("gateway_adaptive_in_flight", float64(inFlight), map[string]string{"service": al.service})
+	return &limitToken{limiter: al, inFlight: inFlight}, nil
+}
+
+// onSample adjusts the limit from one completed attempt
+func (al *adaptiveLimiter) onSample(latency time.Duration, inFlight int, overloaded bool) {
+	al.mutex.Lock()
+	defer al.mutex.Unlock()
+
+	now := time.Now()
+	rtt := float64(latency)
+	if al.longRTT == 0 {
+		al.shortRTT, al.longRTT = rtt, rtt
+	} else if !overloaded {
+		al.shortRTT += (rtt - al.shortRTT) * shortRTTWeight
+		// The baseline follows faster latency at once but slower latency only over
+		// baselineWindow, so load-induced queueing does not become the new normal
+		if rtt < al.longRTT {
+			al.longRTT += (rtt - al.longRTT) * shortRTTWeight
+		} else {
+			weight := math.Min(1, float64(now.Sub(al.lastSample))/float64(baselineWindow))
+			al.longRTT += (rtt - al.longRTT) * weight
+		}
+	}
+	al.lastSample = now
+	// Only grow when the current limit is actually being used
+	utilised := float64(inFlight) >= al.limit/2
+
+	switch {
+	case overloaded || (al.algorithm == LimitAIMD && rtt > al.longRTT*al.tolerance):
+		// Back off at most once per round trip so one slow burst is not punished repeatedly
+		if now.Sub(al.lastBackoff) > time.Duration(al.longRTT) {
+			al.limit *= al.backoffRatio
+			al.lastBackoff = now
+		}
+	case al.algorithm == LimitAIMD:
+		if utilised {
+			// Grows by about one per limit's worth of completed requests
+			al.limit += 1 / al.limit
+		}
+	default:
+		// Shrink in proportion to how far latency has risen beyond the tolerance,
+		// otherwise leave room for a queue of sqrt(limit) requests
+		gradient := math.Max(0.5, math.Min(1, al.tolerance*al.longRTT/al.shortRTT))
+		target := al.limit * gradient
+		if gradient == 1 && utilised {
+			target += math.Sqrt(al.limit)
+		}
+		// Each sample moves the limit a share of the way, so a limit's worth of
+		// samples moves it by limitSmoothing of the way to the target
+		al.limit += (target - al.limit) * limitSmoothing / al.limit
+	}
+	al.limit = math.Max(al.minLimit, math.Min(al.limit, al.maxLimit))
+	al.publish()
+}
+
+// release frees a slot
+func (al *adaptiveLimiter) release() {
+	al.mutex.Lock()
+	al.inFlight--
+	inFlight := al.inFlight
+	al.mutex.Unlock()
+	al.metrics.SetGauge("gateway_adaptive_in_flight", float64(inFlight), map[string]string{"service": al.service})
+}
+
+// publish reports the current limit; callers hold the mutex
+func (al *adaptiveLimiter) publish() {
+	al.metrics.SetGauge("gateway_adaptive_limit", math.Floor(al.limit), map[string]string{"service": al.service})
+}
+
+// limitToken is a slot taken from an adaptive limiter for one attempt
+type limitToken struct {
+	limiter  *adaptiveLimiter
+	inFlight int
+	once     sync.Once
+}
+
+// record feeds the outcome of the attempt into the limiter. Attempts cancelled
+// by the caller, such as a losing hedge, say nothing about the service.
+func (lt *limitToken) record(latency time.Duration, resp *http.Response, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	overloaded := err != nil
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			overloaded = true
+		}
+	}
+	lt.limiter.onSample(latency, lt.inFlight, overloaded)
+}
+
+// release frees the slot; it is safe to call more than once
+func (lt *limitToken) release() {
+	lt.once.Do(lt.limiter.release)
+}
+
+// orDefault returns value, or fallback when value is not positive
+func orDefault(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,214 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+)
+
+func TestNewAdaptiveLimiter(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           config.AdaptiveLimitConfig
+		wantAlgorithm string
+		wantLimit     float64
+		wantTolerance float64
+		wantBackoff   float64
+		wantErr       bool
+	}{
+		{"defaults", config.AdaptiveLimitConfig{}, LimitGradient, defaultInitialLimit, defaultTolerance, defaultBackoffRatio, false},
+		{"aimd", config.AdaptiveLimitConfig{Algorithm: LimitAIMD, InitialLimit: 20, Tolerance: 2, BackoffRatio: 0.5}, LimitAIMD, 20, 2, 0.5, false},
+		{"initial limit clamped to max", config.AdaptiveLimitConfig{InitialLimit: 50, MaxLimit: 30}, LimitGradient, 30, defaultTolerance, defaultBackoffRatio, false},
+		{"initial limit clamped to min", config.AdaptiveLimitConfig{InitialLimit: 2, MinLimit: 5}, LimitGradient, 5, defaultTolerance, defaultBackoffRatio, false},
+		{"out of range tolerance and backoff", config.AdaptiveLimitConfig{Tolerance: 0.5, BackoffRatio: 1.2}, LimitGradient, defaultInitialLimit, defaultTolerance, defaultBackoffRatio, false},
+		{"unknown algorithm", config.AdaptiveLimitConfig{Algorithm: "vegas"}, "", 0, 0, 0, true},
+		{"min above max", config.AdaptiveLimitConfig{MinLimit: 10, MaxLimit: 5}, "", 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := newAdaptiveLimiter("svc", tt.cfg, metrics.NewCollector())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newAdaptiveLimiter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if limiter.algorithm != tt.wantAlgorithm || limiter.limit != tt.wantLimit ||
+				limiter.tolerance != tt.wantTolerance || limiter.backoffRatio != tt.wantBackoff {
+				t.Errorf("limiter = %s limit %v tolerance %v backoff %v, want %s limit %v tolerance %v backoff %v",
+					limiter.algorithm, limiter.limit, limiter.tolerance, limiter.backoffRatio,
+					tt.wantAlgorithm, tt.wantLimit, tt.wantTolerance, tt.wantBackoff)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimiterAcquire(t *testing.T) {
+	limiter, err := newAdaptiveLimiter("svc", config.AdaptiveLimitConfig{InitialLimit: 2}, metrics.NewCollector())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := limiter.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.acquire(); err != nil {
+		t.Fatal(err)
+	}
+
+	var limitErr *domain.ConcurrencyLimitError
+	if _, err := limiter.acquire(); !errors.As(err, &limitErr) || limitErr.Limit != 2 {
+		t.Fatalf("acquire above the limit error = %v, want a concurrency limit error", err)
+	}
+
+	// Releasing twice frees a single slot
+	first.release()
+	first.release()
+	if limiter.inFlight != 1 {
+		t.Errorf("in flight = %d after release, want 1", limiter.inFlight)
+	}
+	if _, err := limiter.acquire(); err != nil {
+		t.Errorf("acquire after release error = %v", err)
+	}
+}
+
+func TestAdaptiveLimiterAdjusts(t *testing.T) {
+	type sample struct {
+		latency    time.Duration
+		inFlight   int
+		overloaded bool
+		count      int
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.AdaptiveLimitConfig
+		samples   []sample
+		wantTrend int // 1 grows, -1 shrinks, 0 unchanged
+	}{
+		{
+			name:      "gradient grows while latency holds and the limit is used",
+			samples:   []sample{{latency: 10 * time.Millisecond, inFlight: 10, count: 50}},
+			wantTrend: 1,
+		},
+		{
+			name:      "gradient holds while the limit is unused",
+			samples:   []sample{{latency: 10 * time.Millisecond, inFlight: 1, count: 50}},
+			wantTrend: 0,
+		},
+		{
+			name: "gradient shrinks when latency rises",
+			samples: []sample{
+				{latency: 10 * time.Millisecond, inFlight: 10, count: 1},
+				{latency: 100 * time.Millisecond, inFlight: 10, count: 50},
+			},
+			wantTrend: -1,
+		},
+		{
+			name:      "overload backs off",
+			samples:   []sample{{latency: 10 * time.Millisecond, inFlight: 10, overloaded: true, count: 1}},
+			wantTrend: -1,
+		},
+		{
+			name:      "aimd grows additively",
+			cfg:       config.AdaptiveLimitConfig{Algorithm: LimitAIMD},
+			samples:   []sample{{latency: 10 * time.Millisecond, inFlight: 10, count: 50}},
+			wantTrend: 1,
+		},
+		{
+			name: "aimd backs off on slow responses",
+			cfg:  config.AdaptiveLimitConfig{Algorithm: LimitAIMD},
+			samples: []sample{
+				{latency: 10 * time.Millisecond, inFlight: 10, count: 1},
+				{latency: 100 * time.Millisecond, inFlight: 10, count: 1},
+			},
+			wantTrend: -1,
+		},
+		{
+			name:      "growth stops at the max limit",
+			cfg:       config.AdaptiveLimitConfig{InitialLimit: 10, MaxLimit: 10},
+			samples:   []sample{{latency: 10 * time.Millisecond, inFlight: 10, count: 50}},
+			wantTrend: 0,
+		},
+		{
+			name:      "backoff stops at the min limit",
+			cfg:       config.AdaptiveLimitConfig{InitialLimit: 10, MinLimit: 10},
+			samples:   []sample{{latency: 10 * time.Millisecond, inFlight: 10, overloaded: true, count: 1}},
+			wantTrend: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := newAdaptiveLimiter("svc", tt.cfg, metrics.NewCollector())
+			if err != nil {
+				t.Fatal(err)
+			}
+			initial := limiter.limit
+			for _, s := range tt.samples {
+				for i := 0; i < s.count; i++ {
+					limiter.onSample(s.latency, s.inFlight, s.overloaded)
+				}
+			}
+
+			trend := 0
+			switch {
+			case limiter.limit > initial:
+				trend = 1
+			case limiter.limit < initial:
+				trend = -1
+			}
+			if trend != tt.wantTrend {
+				t.Errorf("limit went from %v to %v, want trend %d", initial, limiter.limit, tt.wantTrend)
+			}
+		})
+	}
+}
+
+func TestLimitTokenRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		resp       *http.Response
+		err        error
+		wantShrink bool
+		wantSample bool
+	}{
+		{"success", &http.Response{StatusCode: http.StatusOK}, nil, false, true},
+		{"client error is not overload", &http.Response{StatusCode: http.StatusNotFound}, nil, false, true},
+		{"too many requests", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, true, true},
+		{"service unavailable", &http.Response{StatusCode: http.StatusServiceUnavailable}, nil, true, true},
+		{"connection error", nil, errors.New("connection refused"), true, true},
+		{"cancelled by the caller", nil, context.Canceled, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := newAdaptiveLimiter("svc", config.AdaptiveLimitConfig{}, metrics.NewCollector())
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := limiter.acquire()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer token.release()
+
+			initial := limiter.limit
+			token.record(10*time.Millisecond, tt.resp, tt.err)
+			if shrunk := limiter.limit < initial; shrunk != tt.wantShrink {
+				t.Errorf("limit went from %v to %v, want shrink %v", initial, limiter.limit, tt.wantShrink)
+			}
+			if sampled := !limiter.lastSample.IsZero(); sampled != tt.wantSample {
+				t.Errorf("sampled = %v, want %v", sampled, tt.wantSample)
+			}
+		})
+	}
+}
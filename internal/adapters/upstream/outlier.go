@@ -0,0 +1,208 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+const (
+	defaultConsecutiveErrors = 5
+	defaultMinRequests       = 20
+	defaultOutlierInterval   = 10 * time.Second
+	defaultBaseEjectionTime  = 30 * time.Second
+	defaultMaxEjectionTime   = 5 * time.Minute
+	defaultMaxEjectedPercent = 50
+)
+
+// Ejection reasons reported in logs and metrics
+const (
+	ejectConsecutiveErrors = "consecutive_errors"
+	ejectErrorRate         = "error_rate"
+)
+
+// outlierDetector ejects instances from load balancing when real traffic shows
+// them failing: too many 5xx responses or connection errors in a row, or too
+// high an error rate within an interval. Each repeated ejection lasts twice as
+// long as the previous one, and at most a share of the instances is ejected at once.
+type outlierDetector struct {
+	service           string
+	consecutiveErrors int
+	errorRate         float64
+	minRequests       int
+	interval          time.Duration
+	baseEjectionTime  time.Duration
+	maxEjectionTime   time.Duration
+	maxEjectedPercent int
+	endpoints         map[string]*outlierState
+	mutex             sync.Mutex
+	metrics           ports.MetricsCollector
+	logger            ports.Logger
+}
+
+// outlierState is the recent traffic outcome of one instance
+type outlierState struct {
+	endpoint     string
+	consecutive  int
+	requests     int
+	failures     int
+	windowStart  time.Time
+	ejections    int
+	ejectedUntil time.Time
+	ejected      bool
+}
+
+// newOutlierDetector creates a detector from the service configuration
+func newOutlierDetector(service string, cfg config.OutlierDetectionConfig, metrics ports.MetricsCollector, logger ports.Logger) (*outlierDetector, error) {
+	if cfg.ErrorRate < 0 || cfg.ErrorRate > 100 {
+		return nil, fmt.Errorf("outlier detection error_rate must be between 0 and 100")
+	}
+	if cfg.MaxEjectedPercent < 0 || cfg.MaxEjectedPercent > 100 {
+		return nil, fmt.Errorf("outlier detection max_ejected_percent must be between 0 and 100")
+	}
+
+	od := &outlierDetector{
+		service:           service,
+		consecutiveErrors: orDefault(cfg.ConsecutiveErrors, defaultConsecutiveErrors),
+		errorRate:         cfg.ErrorRate,
+		minRequests:       orDefault(cfg.MinRequests, defaultMinRequests),
+		interval:          orDefaultDuration(cfg.Interval, defaultOutlierInterval),
+		baseEjectionTime:  orDefaultDuration(cfg.BaseEjectionTime, defaultBaseEjectionTime),
+		maxEjectionTime:   orDefaultDuration(cfg.MaxEjectionTime, defaultMaxEjectionTime),
+		maxEjectedPercent: orDefault(cfg.MaxEjectedPercent, defaultMaxEjectedPercent),
+		endpoints:         make(map[string]*outlierState),
+		metrics:           metrics,
+		logger:            logger,
+	}
+	if od.maxEjectionTime < od.baseEjectionTime {
+		return nil, fmt.Errorf("outlier detection max_ejection_time %s is shorter than base_ejection_time %s", od.maxEjectionTime, od.baseEjectionTime)
+	}
+	return od, nil
+}
+
+// record feeds the outcome of one attempt against an instance. Attempts cancelled
+// by the caller, such as a losing hedge, say nothing about the instance.
+func (od *outlierDetector) record(endpoint string, total int, resp *http.Response, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	failed := err != nil || (resp != nil && resp.StatusCode >= http.StatusInternalServerError)
+
+	od.mutex.Lock()
+	defer od.mutex.Unlock()
+
+	now := time.Now()
+	state, exists := od.endpoints[endpoint]
+	if !exists {
+		state = &outlierState{endpoint: endpoint, windowStart: now}
+		od.endpoints[endpoint] = state
+	}
+	if now.Sub(state.windowStart) >= od.interval {
+		// A clean interval outside ejection earns back one step of the ejection time
+		if !state.ejected && state.failures == 0 && state.ejections > 0 {
+			state.ejections--
+		}
+		state.requests, state.failures, state.windowStart = 0, 0, now
+	}
+
+	state.requests++
+	if !failed {
+		state.consecutive = 0
+		return
+	}
+	state.failures++
+	state.consecutive++
+
+	if state.ejected {
+		return
+	}
+	switch {
+	case state.consecutive >= od.consecutiveErrors:
+		od.eject(endpoint, state, total, ejectConsecutiveErrors, now)
+	case od.errorRate > 0 && state.requests >= od.minRequests &&
+		float64(state.failures)*100/float64(state.requests) >= od.errorRate:
+		od.eject(endpoint, state, total, ejectErrorRate, now)
+	}
+}
+
+// eject takes an instance out of balancing unless too many already are; callers hold the mutex
+func (od *outlierDetector) eject(endpoint string, state *outlierState, total int, reason string, now time.Time) {
+	ejected := 0
+	for _, other := range od.endpoints {
+		if od.stillEjected(other, now) {
+			ejected++
+		}
+	}
+	// One instance can always be ejected, otherwise small pools would never eject
+	allowed := total * od.maxEjectedPercent / 100
+	if allowed < 1 {
+		allowed = 1
+	}
+	if ejected >= allowed {
+		// Start counting again so the instance is reconsidered after another run of failures
+		state.consecutive = 0
+		od.metrics.IncrementCounter("gateway_outlier_ejections_skipped_total", map[string]string{"service": od.service})
+		od.logger.Warn("Outlier ejection skipped, too many instances ejected", map[string]interface{}{
+			"service":  od.service,
+			"endpoint": endpoint,
+			"reason":   reason,
+			"ejected":  ejected,
+		})
+		return
+	}
+
+	duration := od.baseEjectionTime << state.ejections
+	if duration > od.maxEjectionTime || duration <= 0 {
+		duration = od.maxEjectionTime
+	}
+	state.ejections++
+	state.ejected = true
+	state.ejectedUntil = now.Add(duration)
+	state.consecutive, state.requests, state.failures, state.windowStart = 0, 0, 0, now
+
+	labels := map[string]string{"service": od.service, "endpoint": endpoint}
+	od.metrics.IncrementCounter("gateway_outlier_ejections_total", map[string]string{"service": od.service, "endpoint": endpoint, "reason": reason})
+	od.metrics.SetGauge("gateway_outlier_ejected", 1, labels)
+	od.logger.Warn("⚠️ Upstream instance ejected", map[string]interface{}{
+		"service":  od.service,
+		"endpoint": endpoint,
+		"reason":   reason,
+		"duration": duration.String(),
+	})
+}
+
+// isEjected reports whether an instance is currently out of balancing
+func (od *outlierDetector) isEjected(endpoint string) bool {
+	od.mutex.Lock()
+	defer od.mutex.Unlock()
+	state, exists := od.endpoints[endpoint]
+	return exists && od.stillEjected(state, time.Now())
+}
+
+// stillEjected ends an ejection whose time is up; callers hold the mutex
+func (od *outlierDetector) stillEjected(state *outlierState, now time.Time) bool {
+	if !state.ejected || now.Before(state.ejectedUntil) {
+		return state.ejected
+	}
+	state.ejected = false
+	od.metrics.SetGauge("gateway_outlier_ejected", 0, map[string]string{"service": od.service, "endpoint": state.endpoint})
+	od.logger.Info("💚 Upstream instance returned to balancing", map[string]interface{}{
+		"service":  od.service,
+		"endpoint": state.endpoint,
+	})
+	return false
+}
+
+// orDefaultDuration returns value, or fallback when value is not positive
+func orDefaultDuration(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
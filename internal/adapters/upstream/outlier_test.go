@@ -0,0 +1,218 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
+)
+
+var (
+	status500 = &http.Response{StatusCode: http.StatusInternalServerError}
+	status200 = &http.Response{StatusCode: http.StatusOK}
+	status404 = &http.Response{StatusCode: http.StatusNotFound}
+)
+
+func newTestOutlierDetector(t *testing.T, cfg config.OutlierDetectionConfig) (*outlierDetector, *metrics.Collector) {
+	t.Helper()
+	collector := metrics.NewCollector()
+	od, err := newOutlierDetector("plants", cfg, collector, logger.NewLogger("error", "json", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return od, collector
+}
+
+func TestNewOutlierDetector(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.OutlierDetectionConfig
+		wantErr bool
+	}{
+		{"defaults", config.OutlierDetectionConfig{}, false},
+		{"error rate above 100", config.OutlierDetectionConfig{ErrorRate: 120}, true},
+		{"negative max ejected percent", config.OutlierDetectionConfig{MaxEjectedPercent: -1}, true},
+		{"max ejection shorter than base", config.OutlierDetectionConfig{BaseEjectionTime: time.Minute, MaxEjectionTime: time.Second}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			od, err := newOutlierDetector("plants", tt.cfg, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newOutlierDetector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (od.consecutiveErrors != defaultConsecutiveErrors || od.baseEjectionTime != defaultBaseEjectionTime || od.maxEjectedPercent != defaultMaxEjectedPercent) {
+				t.Errorf("detector = %+v, want the defaults", od)
+			}
+		})
+	}
+}
+
+func TestOutlierConsecutiveErrors(t *testing.T) {
+	od, collector := newTestOutlierDetector(t, config.OutlierDetectionConfig{ConsecutiveErrors: 3})
+	const endpoint = "http://10.0.0.5:8080"
+
+	// Client errors, cancelled attempts and a success in between do not count
+	od.record(endpoint, 2, status500, nil)
+	od.record(endpoint, 2, status500, nil)
+	od.record(endpoint, 2, status200, nil)
+	od.record(endpoint, 2, status404, nil)
+	od.record(endpoint, 2, nil, context.Canceled)
+	od.record(endpoint, 2, status500, nil)
+	od.record(endpoint, 2, nil, errors.New("connection refused"))
+	if od.isEjected(endpoint) {
+		t.Fatal("ejected before three errors in a row")
+	}
+
+	od.record(endpoint, 2, status500, nil)
+	if !od.isEjected(endpoint) {
+		t.Fatal("not ejected after three errors in a row")
+	}
+	if got := counterValue(collector, "gateway_outlier_ejections_total", map[string]string{"reason": ejectConsecutiveErrors}); got != 1 {
+		t.Errorf("ejections = %v, want 1", got)
+	}
+
+	// The instance returns once its ejection time is up
+	od.endpoints[endpoint].ejectedUntil = time.Now().Add(-time.Millisecond)
+	if od.isEjected(endpoint) {
+		t.Error("still ejected after the ejection time")
+	}
+}
+
+func TestOutlierErrorRate(t *testing.T) {
+	od, collector := newTestOutlierDetector(t, config.OutlierDetectionConfig{ConsecutiveErrors: 100, ErrorRate: 50, MinRequests: 10})
+	const endpoint = "http://10.0.0.5:8080"
+
+	for i := 0; i < 9; i++ {
+		if i%2 == 0 {
+			od.record(endpoint, 2, status500, nil)
+		} else {
+			od.record(endpoint, 2, status200, nil)
+		}
+	}
+	if od.isEjected(endpoint) {
+		t.Fatal("ejected before the minimum number of requests")
+	}
+	od.record(endpoint, 2, status200, nil)
+	od.record(endpoint, 2, status500, nil)
+	if !od.isEjected(endpoint) {
+		t.Fatal("not ejected at a 50% error rate")
+	}
+	if got := counterValue(collector, "gateway_outlier_ejections_total", map[string]string{"reason": ejectErrorRate}); got != 1 {
+		t.Errorf("ejections = %v, want 1", got)
+	}
+}
+
+func TestOutlierEjectionBackoff(t *testing.T) {
+	od, _ := newTestOutlierDetector(t, config.OutlierDetectionConfig{
+		ConsecutiveErrors: 1,
+		BaseEjectionTime:  time.Minute,
+		MaxEjectionTime:   3 * time.Minute,
+	})
+	const endpoint = "http://10.0.0.5:8080"
+
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		before := time.Now()
+		od.record(endpoint, 2, status500, nil)
+		state := od.endpoints[endpoint]
+		if got := state.ejectedUntil.Sub(before); got < want || got > want+time.Second {
+			t.Errorf("ejection %d lasted %v, want %v", state.ejections, got, want)
+		}
+		state.ejectedUntil = time.Now().Add(-time.Millisecond)
+		od.isEjected(endpoint)
+	}
+
+	// A clean interval earns back one step
+	state := od.endpoints[endpoint]
+	ejections := state.ejections
+	state.windowStart = time.Now().Add(-2 * od.interval)
+	od.record(endpoint, 2, status200, nil)
+	if state.ejections != ejections-1 {
+		t.Errorf("ejections = %d after a clean interval, want %d", state.ejections, ejections-1)
+	}
+}
+
+func TestOutlierMaxEjectedPercent(t *testing.T) {
+	od, collector := newTestOutlierDetector(t, config.OutlierDetectionConfig{ConsecutiveErrors: 1, MaxEjectedPercent: 50})
+	endpoints := []string{"http://10.0.0.1:8080", "http://10.0.0.2:8080", "http://10.0.0.3:8080", "http://10.0.0.4:8080"}
+
+	for _, endpoint := range endpoints {
+		od.record(endpoint, len(endpoints), status500, nil)
+	}
+	ejected := 0
+	for _, endpoint := range endpoints {
+		if od.isEjected(endpoint) {
+			ejected++
+		}
+	}
+	if ejected != 2 {
+		t.Errorf("ejected = %d of 4, want 2", ejected)
+	}
+	if got := counterValue(collector, "gateway_outlier_ejections_skipped_total", nil); got != 2 {
+		t.Errorf("skipped ejections = %v, want 2", got)
+	}
+
+	// A single instance can still be ejected
+	single, _ := newTestOutlierDetector(t, config.OutlierDetectionConfig{ConsecutiveErrors: 1, MaxEjectedPercent: 10})
+	single.record(endpoints[0], 1, status500, nil)
+	if !single.isEjected(endpoints[0]) {
+		t.Error("the only instance of a small pool was not ejected")
+	}
+}
+
+func TestTransportEjectsFailingInstances(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer working.Close()
+
+	transport, _ := newTestTransport(t, config.ServiceConfig{
+		URL:              "http://plants:8080",
+		OutlierDetection: &config.OutlierDetectionConfig{ConsecutiveErrors: 2},
+	}, failing.URL, working.URL)
+
+	// Injected failures say nothing about the instances
+	transport.injector = services.NewFaultInjector(true, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+	transport.faults = []ports.FaultRule{{Type: services.FaultAbort, Status: http.StatusServiceUnavailable, Percentage: 100}}
+	for i := 0; i < 6; i++ {
+		resp, err := get(t, transport, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if transport.outliers.isEjected(failing.URL) || transport.outliers.isEjected(working.URL) {
+		t.Fatal("injected faults ejected an instance")
+	}
+
+	transport.faults = nil
+	for i := 0; i < 4; i++ {
+		resp, err := get(t, transport, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if !transport.outliers.isEjected(failing.URL) {
+		t.Fatal("the failing instance was not ejected")
+	}
+	for i := 0; i < 4; i++ {
+		resp, err := get(t, transport, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("status = %d, want only the working instance", resp.StatusCode)
+		}
+	}
+}
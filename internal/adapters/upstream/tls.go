@@ -0,0 +1,286 @@
+package upstream
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+)
+
+// tlsFiles keeps the CA bundle and client certificate of a service and reloads
+// them when the files change on disk, so rotated certificates are picked up
+// by the next handshake without restarting the gateway
+type tlsFiles struct {
+	config   config.TLSConfig
+	pool     *x509.CertPool
+	cert     *tls.Certificate
+	modTimes map[string]time.Time
+	mutex    sync.Mutex
+}
+
+// newTLSFiles loads the files referenced by a TLS configuration
+func newTLSFiles(cfg config.TLSConfig) (*tlsFiles, error) {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("cert_file and key_file must be set together")
+	}
+
+	files := &tlsFiles{
+		config:   cfg,
+		modTimes: make(map[string]time.Time),
+	}
+	if err := files.reload(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// configureTLS applies a service's TLS settings to its transport. Certificates are
+// checked against server_name, else the host of the service URL when it is a name,
+// so discovered IP endpoints are still verified as the service.
+func configureTLS(base *http.Transport, cfg config.TLSConfig, serviceURL string) error {
+	if cfg.ServerName == "" {
+		cfg.ServerName = serviceServerName(serviceURL)
+	}
+	tlsConfig, files, err := buildTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	base.TLSClientConfig = tlsConfig
+	if tlsConfig.VerifyConnection != nil {
+		// Each connection must know the name it verifies against, which the shared
+		// VerifyConnection callback cannot see, so connections are set up here
+		base.DialTLSContext = files.dialTLS(tlsConfig)
+	}
+	return nil
+}
+
+// serviceServerName returns the host of an https service URL when it is a DNS name
+func serviceServerName(serviceURL string) string {
+	parsed, err := url.Parse(serviceURL)
+	if err != nil || parsed.Scheme != "https" {
+		return ""
+	}
+	host := parsed.Hostname()
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+// buildTLSConfig creates the client TLS configuration for a service
+func buildTLSConfig(cfg config.TLSConfig) (*tls.Config, *tlsFiles, error) {
+	minVersion, err := parseTLSVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := newTLSFiles(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         minVersion,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CertFile != "" {
+		tlsConfig.GetClientCertificate = files.clientCertificate
+	}
+	if cfg.CAFile != "" && !cfg.InsecureSkipVerify {
+		// The CA bundle can change at runtime, so the chain is verified against the
+		// current pool in VerifyConnection instead of a pool fixed at startup
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return files.verifyConnection(state, cfg.ServerName)
+		}
+	}
+	return tlsConfig, files, nil
+}
+
+// dialTLS returns a dialer that verifies every upstream certificate against the
+// configured server name, or the dialed host (which may be an IP) when none is set
+func (f *tlsFiles) dialTLS(tlsConfig *tls.Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		name := f.config.ServerName
+		if name == "" {
+			name = host
+		}
+
+		connConfig := tlsConfig.Clone()
+		connConfig.ServerName = name
+		connConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return f.verifyConnection(state, name)
+		}
+
+		rawConn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		conn := tls.Client(rawConn, connConfig)
+		if err := conn.HandshakeContext(ctx); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// clientCertificate returns the current client certificate for mutual TLS
+func (f *tlsFiles) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if err := f.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.cert, nil
+}
+
+// verifyConnection verifies the server certificate chain against the current CA
+// bundle and checks that it was issued for name
+func (f *tlsFiles) verifyConnection(state tls.ConnectionState, name string) error {
+	if name == "" {
+		return errors.New("no server name to verify the upstream certificate against")
+	}
+	if err := f.reloadIfChanged(); err != nil {
+		return err
+	}
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("upstream presented no certificate")
+	}
+
+	f.mutex.Lock()
+	pool := f.pool
+	f.mutex.Unlock()
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		DNSName:       name,
+	})
+	return err
+}
+
+// reloadIfChanged reloads the files when any of them has a new modification time
+func (f *tlsFiles) reloadIfChanged() error {
+	f.mutex.Lock()
+	changed := false
+	for _, path := range []string{f.config.CAFile, f.config.CertFile, f.config.KeyFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			f.mutex.Unlock()
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if !info.ModTime().Equal(f.modTimes[path]) {
+			changed = true
+		}
+	}
+	f.mutex.Unlock()
+
+	if !changed {
+		return nil
+	}
+	if err := f.reload(); err != nil {
+		// Files may be mid-rotation; keep using the previous material and retry on the next handshake
+		f.mutex.Lock()
+		loaded := f.pool != nil || f.cert != nil
+		f.mutex.Unlock()
+		if loaded {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// reload reads the CA bundle and client key pair from disk
+func (f *tlsFiles) reload() error {
+	var pool *x509.CertPool
+	if f.config.CAFile != "" {
+		data, err := os.ReadFile(f.config.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in CA file %s", f.config.CAFile)
+		}
+	}
+
+	var cert *tls.Certificate
+	if f.config.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(f.config.CertFile, f.config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cert = &pair
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, path := range []string{f.config.CAFile, f.config.CertFile, f.config.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.pool = pool
+	f.cert = cert
+	f.modTimes = modTimes
+	return nil
+}
+
+// parseTLSVersion converts a configured minimum version to its tls constant
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS min_version %q", version)
+	}
+}
+
+// isTLSError reports whether an error was caused by the TLS handshake or certificate checks
+func isTLSError(err error) bool {
+	var (
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	return errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &verifyErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
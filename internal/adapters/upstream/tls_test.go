@@ -0,0 +1,193 @@
+package upstream
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+)
+
+// testCA is a throwaway certificate authority
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a server certificate for the given DNS name
+func (ca *testCA) issue(t *testing.T, dnsName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// newTLSServer starts an upstream serving cert, without logging failed handshakes
+func newTLSServer(cert tls.Certificate) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	return server
+}
+
+// writeRotated replaces a file and moves its modification time forward so the
+// change is seen even within the file system's timestamp resolution
+func writeRotated(t *testing.T, path string, data []byte, generation int) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Duration(generation) * time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSReloadsRotatedCABundle(t *testing.T) {
+	oldCA, newCA := newTestCA(t, "old-ca"), newTestCA(t, "new-ca")
+
+	// The upstream already serves a certificate from the new CA
+	server := newTLSServer(newCA.issue(t, "plants.internal"))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeRotated(t, caFile, oldCA.pem, 0)
+
+	base := &http.Transport{}
+	if err := configureTLS(base, config.TLSConfig{CAFile: caFile}, "https://plants.internal"); err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: base}
+	// The test server listens on an IP; the certificate is checked against the service name
+	endpoint := server.URL
+
+	if _, err := client.Get(endpoint); err == nil || !isTLSError(err) {
+		t.Fatalf("request with the old CA error = %v, want a TLS error", err)
+	}
+
+	// Mid-rotation garbage keeps the previous bundle instead of failing open or hard
+	writeRotated(t, caFile, []byte("not a certificate"), 1)
+	if _, err := client.Get(endpoint); err == nil || !isTLSError(err) {
+		t.Fatalf("request during rotation error = %v, want a TLS error", err)
+	}
+
+	writeRotated(t, caFile, newCA.pem, 2)
+	base.CloseIdleConnections()
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		t.Fatalf("request after the CA rotation error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTLSVerifiesServiceName(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	server := newTLSServer(ca.issue(t, "reports.internal"))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeRotated(t, caFile, ca.pem, 0)
+
+	tests := []struct {
+		name       string
+		serviceURL string
+		serverName string
+		wantErr    bool
+	}{
+		{"name from the service URL", "https://reports.internal", "", false},
+		{"explicit server name", "https://10.0.0.9", "reports.internal", false},
+		{"certificate for another service", "https://plants.internal", "", true},
+		{"IP service URL without a server name", "https://10.0.0.9", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &http.Transport{}
+			if err := configureTLS(base, config.TLSConfig{CAFile: caFile, ServerName: tt.serverName}, tt.serviceURL); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: base}).Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("request error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildTLSConfigRejectsBadSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+	}{
+		{"unknown min version", config.TLSConfig{MinVersion: "1.1"}},
+		{"cert without key", config.TLSConfig{CertFile: "client.pem"}},
+		{"missing CA file", config.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := buildTLSConfig(tt.cfg); err == nil {
+				t.Error("buildTLSConfig() accepted the configuration")
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package upstream
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/config"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/services"
+)
+
+// TransportRegistry holds one HTTP transport per upstream service
+type TransportRegistry struct {
+	transports map[string]*serviceTransport
+	discovery  ports.ServiceDiscovery
+	injector   *services.FaultInjector
+	health     *HealthChecker
+	metrics    ports.MetricsCollector
+	mutex      sync.RWMutex
+	logger     ports.Logger
+}
+
+// serviceTransport is the round tripper used for every call to a single service.
+// It enforces the service bulkhead and adaptive limit and spreads requests across
+// the discovered endpoints of the service that pass their health checks and are
+// not ejected as outliers. Every strategy, including orchestrated sub-calls, goes
+// through it.
+type serviceTransport struct {
+	service   string
+	base      *http.Transport
+	discovery ports.ServiceDiscovery
+	health    *HealthChecker
+	outliers  *outlierDetector
+	balancer  *roundRobin
+	bulkhead  *services.Bulkhead
+	limiter   *adaptiveLimiter
+	injector  *services.FaultInjector
+	faults    []ports.FaultRule
+	budget    *attemptBudget
+	latency   *routeLatencies
+	metrics   ports.MetricsCollector
+	logger    ports.Logger
+}
+
+// NewTransportRegistry creates a new transport registry
+func NewTransportRegistry(discovery ports.ServiceDiscovery, injector *services.FaultInjector, metrics ports.MetricsCollector, logger ports.Logger) *TransportRegistry {
+	return &TransportRegistry{
+		transports: make(map[string]*serviceTransport),
+		discovery:  discovery,
+		injector:   injector,
+		health:     newHealthChecker(discovery, metrics, logger),
+		metrics:    metrics,
+		logger:     logger,
+	}
+}
+
+// Update rebuilds the transports for the given services. Nothing is replaced if
+// any service has an invalid TLS configuration.
+func (tr *TransportRegistry) Update(services map[string]config.ServiceConfig) error {
+	transports := make(map[string]*serviceTransport, len(services))
+	for name, service := range services {
+		transport, err := tr.newServiceTransport(name, service)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+		transports[name] = transport
+	}
+
+	tr.mutex.Lock()
+	previous := tr.transports
+	tr.transports = transports
+	tr.mutex.Unlock()
+	tr.health.update(services, transports)
+
+	for _, transport := range previous {
+		transport.base.CloseIdleConnections()
+	}
+	return nil
+}
+
+// HealthChecker returns the checker probing the services that configure a health check
+func (tr *TransportRegistry) HealthChecker() *HealthChecker {
+	return tr.health
+}
+
+// Stop stops the background health checks
+func (tr *TransportRegistry) Stop() {
+	tr.health.stop()
+}
+
+// Transport returns the round tripper for a service, or nil when the service is unknown
+func (tr *TransportRegistry) Transport(serviceName string) http.RoundTripper {
+	tr.mutex.RLock()
+	defer tr.mutex.RUnlock()
+	if transport, exists := tr.transports[serviceName]; exists {
+		return transport
+	}
+	return nil
+}
+
+// newServiceTransport creates the transport for one service
+func (tr *TransportRegistry) newServiceTransport(name string, service config.ServiceConfig) (*serviceTransport, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	if service.TLS != nil {
+		if err := configureTLS(base, *service.TLS, service.URL); err != nil {
+			return nil, err
+		}
+
+		if service.TLS.InsecureSkipVerify {
+			tr.logger.Warn("⚠️ TLS certificate verification disabled for upstream", map[string]interface{}{
+				"service": name,
+				"url":     service.URL,
+			})
+		}
+		tr.logger.Info("🔒 Upstream TLS configured", map[string]interface{}{
+			"service":     name,
+			"ca_file":     service.TLS.CAFile,
+			"mutual_tls":  service.TLS.CertFile != "",
+			"min_version": service.TLS.MinVersion,
+			"server_name": service.TLS.ServerName,
+		})
+	} else if serverName := serviceServerName(service.URL); serverName != "" {
+		// Discovered endpoints are dialed by IP; certificates must still name the service
+		base.TLSClientConfig = &tls.Config{ServerName: serverName}
+	}
+
+	var bulkhead *services.Bulkhead
+	if service.Bulkhead != nil && service.Bulkhead.MaxConcurrent > 0 {
+		bulkhead = services.NewBulkhead(services.BulkheadScopeService, name, ports.BulkheadConfig{
+			MaxConcurrent: service.Bulkhead.MaxConcurrent,
+			MaxQueue:      service.Bulkhead.MaxQueue,
+			MaxWait:       service.Bulkhead.MaxWait,
+		}, tr.metrics)
+	}
+
+	var limiter *adaptiveLimiter
+	if service.AdaptiveLimit != nil {
+		var err error
+		if limiter, err = newAdaptiveLimiter(name, *service.AdaptiveLimit, tr.metrics); err != nil {
+			return nil, err
+		}
+	}
+
+	var outliers *outlierDetector
+	if service.OutlierDetection != nil {
+		var err error
+		if outliers, err = newOutlierDetector(name, *service.OutlierDetection, tr.metrics, tr.logger); err != nil {
+			return nil, err
+		}
+	}
+
+	var faults []ports.FaultRule
+	for _, fault := range service.Faults {
+		rule := convertFault(fault)
+		if err := services.ValidateFaultRule(rule); err != nil {
+			return nil, err
+		}
+		faults = append(faults, rule)
+	}
+
+	return &serviceTransport{
+		service:   name,
+		base:      base,
+		discovery: tr.discovery,
+		health:    tr.health,
+		outliers:  outliers,
+		balancer:  &roundRobin{},
+		bulkhead:  bulkhead,
+		limiter:   limiter,
+		injector:  tr.injector,
+		faults:    faults,
+		budget:    &attemptBudget{tokens: maxBudgetTokens},
+		latency:   &routeLatencies{},
+		metrics:   tr.metrics,
+		logger:    tr.logger,
+	}, nil
+}
+
+// RoundTrip sends the request and applies the retry and hedge policies of its route
+func (st *serviceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retry, hedge := ports.AttemptPoliciesFromContext(req.Context())
+	if (retry == nil && hedge == nil) || !isReplayable(req) {
+		return st.attempt(req, st.pickEndpoint(""))
+	}
+	return st.roundTripWithPolicies(req, retry, hedge)
+}
+
+// attempt sends the request to one endpoint within the service's adaptive limit
+// and bulkhead and classifies TLS handshake failures
+func (st *serviceTransport) attempt(req *http.Request, endpoint string) (*http.Response, error) {
+	// Shed at once above the adaptive limit rather than queueing for the bulkhead
+	var token *limitToken
+	if st.limiter != nil {
+		var err error
+		if token, err = st.limiter.acquire(); err != nil {
+			st.logger.Warn("Upstream concurrency limit reached", map[string]interface{}{
+				"service": st.service,
+				"error":   err.Error(),
+			})
+			return nil, err
+		}
+	}
+
+	release := func() {}
+	if st.bulkhead != nil {
+		var err error
+		if release, err = st.bulkhead.Acquire(req.Context()); err != nil {
+			if token != nil {
+				token.release()
+			}
+			st.logger.Warn("Upstream bulkhead rejected request", map[string]interface{}{
+				"service": st.service,
+				"error":   err.Error(),
+			})
+			return nil, err
+		}
+	}
+	if token != nil {
+		releaseBulkhead := release
+		release = func() {
+			releaseBulkhead()
+			token.release()
+		}
+	}
+
+	// Without discovered endpoints the request goes to the URL it was built with
+	if endpoint != "" {
+		routed, err := withEndpoint(req, endpoint)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		req = routed
+	}
+
+	start := time.Now()
+	resp, injected, err := st.send(req)
+	// Injected faults must not eject healthy instances or shrink the limit during chaos runs
+	if token != nil && !injected {
+		token.record(time.Since(start), resp, err)
+	}
+	if st.outliers != nil && endpoint != "" && !injected {
+		st.outliers.record(endpoint, len(st.discovery.Endpoints(st.service)), resp, err)
+	}
+	if err != nil {
+		release()
+		if isTLSError(err) {
+			st.logger.Error("Upstream TLS handshake failed", err, map[string]interface{}{
+				"service": st.service,
+				"host":    req.URL.Host,
+			})
+			return nil, &domain.UpstreamTLSError{Service: st.service, Err: err}
+		}
+		return nil, err
+	}
+	// Only routes with a hedge policy read their latency window
+	if _, hedge := ports.AttemptPoliciesFromContext(req.Context()); hedge != nil && !injected {
+		st.latency.tracker(ports.AttemptRouteFromContext(req.Context())).record(time.Since(start))
+	}
+
+	// The slot stays taken until the caller is done with the response body
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// pickEndpoint returns the next endpoint, avoiding exclude when another one is
+// available. Unhealthy and ejected endpoints are skipped unless no other is left.
+func (st *serviceTransport) pickEndpoint(exclude string) string {
+	endpoints := st.discovery.Endpoints(st.service)
+	if len(endpoints) == 0 {
+		return ""
+	}
+	available := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if st.health.isAvailable(st.service, endpoint) && (st.outliers == nil || !st.outliers.isEjected(endpoint)) {
+			available = append(available, endpoint)
+		}
+	}
+	if len(available) > 0 {
+		endpoints = available
+	}
+	endpoint := st.balancer.pick(endpoints)
+	for i := 1; endpoint == exclude && i < len(endpoints); i++ {
+		endpoint = st.balancer.pick(endpoints)
+	}
+	return endpoint
+}
+
+// releasingBody frees a bulkhead slot once the response body is closed or fully read
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Read reads from the body and releases the slot at the end of the stream
+func (rb *releasingBody) Read(p []byte) (int, error) {
+	n, err := rb.ReadCloser.Read(p)
+	if err == io.EOF {
+		rb.release()
+	}
+	return n, err
+}
+
+// Close closes the body and releases the slot
+func (rb *releasingBody) Close() error {
+	err := rb.ReadCloser.Close()
+	rb.release()
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,10 +15,43 @@ import (
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Host         string        `yaml:"host"`
-	Port         int           `yaml:"port"`
-	ReadTimeout  time.Duration `yaml:"read_timeout"`
-	WriteTimeout time.Duration `yaml:"write_timeout"`
+	Host           string             `yaml:"host"`
+	Port           int                `yaml:"port"`
+	ReadTimeout    time.Duration      `yaml:"read_timeout"`
+	WriteTimeout   time.Duration      `yaml:"write_timeout"`
+	MaxBodySize    ByteSize           `yaml:"max_body_size"`
+	MaxHeaderBytes ByteSize           `yaml:"max_header_bytes"`
+	MaxJSONDepth   int                `yaml:"max_json_depth"`
+	TrailingSlash  string             `yaml:"trailing_slash"` // strip, redirect or preserve
+	LoadShedding   LoadSheddingConfig `yaml:"load_shedding"`
+	Shutdown       ShutdownConfig     `yaml:"shutdown"`
+}
+
+// ShutdownConfig controls how the gateway drains on SIGTERM
+type ShutdownConfig struct {
+	DrainDelay time.Duration `yaml:"drain_delay"` // readiness fails this long before the listener closes
+	Timeout    time.Duration `yaml:"timeout"`     // time in-flight requests get to finish
+}
+
+// LoadSheddingConfig sets the limits at which the gateway considers itself overloaded
+type LoadSheddingConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	MaxInFlight   int           `yaml:"max_in_flight"`
+	MaxGoroutines int           `yaml:"max_goroutines"`
+	TargetLatency time.Duration `yaml:"target_latency"` // average request latency considered healthy
+}
+
+// ByteSize is a size in bytes that accepts human-readable YAML values such as "10MB"
+type ByteSize int64
+
+// UnmarshalYAML parses sizes like 1048576, "512KB", "10MB" or "1GB"
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := parseByteSize(value.Value)
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(size)
+	return nil
 }
 
 // CORSConfig holds CORS configuration
@@ -36,21 +70,209 @@ type LoggingConfig struct {
 
 // ServiceConfig holds service endpoint configuration
 type ServiceConfig struct {
-	URL     string        `yaml:"url"`
-	Timeout time.Duration `yaml:"timeout"`
+	URL              string                  `yaml:"url"`
+	Timeout          time.Duration           `yaml:"timeout"`
+	MaxResponseSize  ByteSize                `yaml:"max_response_size,omitempty"`
+	TLS              *TLSConfig              `yaml:"tls,omitempty"`
+	Discovery        *DiscoveryConfig        `yaml:"discovery,omitempty"`
+	Bulkhead         *BulkheadConfig         `yaml:"bulkhead,omitempty"`
+	AdaptiveLimit    *AdaptiveLimitConfig    `yaml:"adaptive_limit,omitempty"`
+	Faults           []FaultConfig           `yaml:"faults,omitempty"`
+	HealthCheck      *HealthCheckConfig      `yaml:"health_check,omitempty"`
+	OutlierDetection *OutlierDetectionConfig `yaml:"outlier_detection,omitempty"`
+	Critical         bool                    `yaml:"critical,omitempty"` // readiness fails while the service is unhealthy
+}
+
+// HealthCheckConfig probes every instance of a service in the background
+type HealthCheckConfig struct {
+	Path               string        `yaml:"path"`                          // e.g. /health
+	Interval           time.Duration `yaml:"interval,omitempty"`            // defaults to 10s
+	Timeout            time.Duration `yaml:"timeout,omitempty"`             // defaults to 2s
+	HealthyThreshold   int           `yaml:"healthy_threshold,omitempty"`   // successes before healthy, defaults to 2
+	UnhealthyThreshold int           `yaml:"unhealthy_threshold,omitempty"` // failures before unhealthy, defaults to 3
+}
+
+// OutlierDetectionConfig ejects instances that fail real traffic from load balancing
+type OutlierDetectionConfig struct {
+	ConsecutiveErrors int           `yaml:"consecutive_errors,omitempty"`  // 5xx or connection errors in a row, defaults to 5
+	ErrorRate         float64       `yaml:"error_rate,omitempty"`          // percentage of failed calls per interval, 0 disables
+	MinRequests       int           `yaml:"min_requests,omitempty"`        // calls per interval before the error rate applies, defaults to 20
+	Interval          time.Duration `yaml:"interval,omitempty"`            // error rate window, defaults to 10s
+	BaseEjectionTime  time.Duration `yaml:"base_ejection_time,omitempty"`  // first ejection, doubled on each repeat, defaults to 30s
+	MaxEjectionTime   time.Duration `yaml:"max_ejection_time,omitempty"`   // defaults to 5m
+	MaxEjectedPercent int           `yaml:"max_ejected_percent,omitempty"` // share of instances ejected at once, defaults to 50
+}
+
+// FaultConfig injects a failure into a share of upstream calls for chaos testing
+type FaultConfig struct {
+	Type       string        `yaml:"type"`             // latency, abort, reset or truncate
+	Delay      time.Duration `yaml:"delay,omitempty"`  // latency: added before the call
+	Status     int           `yaml:"status,omitempty"` // abort: status answered instead of calling upstream
+	Bytes      int64         `yaml:"bytes,omitempty"`  // truncate: body bytes passed through before the cut
+	Percentage float64       `yaml:"percentage"`       // share of calls affected, 0-100
+	Header     string        `yaml:"header,omitempty"` // only when the client sends this header
+}
+
+// FaultInjectionConfig switches fault injection on or off as a whole
+type FaultInjectionConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// AdaptiveLimitConfig adjusts the allowed concurrency to a service from observed latency and errors
+type AdaptiveLimitConfig struct {
+	Algorithm    string  `yaml:"algorithm,omitempty"`     // gradient (default) or aimd
+	InitialLimit int     `yaml:"initial_limit,omitempty"` // defaults to 10
+	MinLimit     int     `yaml:"min_limit,omitempty"`     // defaults to 1
+	MaxLimit     int     `yaml:"max_limit,omitempty"`     // defaults to 500
+	Tolerance    float64 `yaml:"tolerance,omitempty"`     // latency increase tolerated before backing off, defaults to 1.5
+	BackoffRatio float64 `yaml:"backoff_ratio,omitempty"` // limit multiplier on overload, defaults to 0.9
+}
+
+// RetryConfig retries failed GET, HEAD and OPTIONS calls to the route's upstream
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`       // total attempts, including the first
+	RetryOn     []int         `yaml:"retry_on,omitempty"` // status codes, defaults to 502, 503 and 504
+	Backoff     time.Duration `yaml:"backoff,omitempty"`
+}
+
+// HedgeConfig sends a second attempt, preferably to another instance, when the first is slow
+type HedgeConfig struct {
+	Delay      time.Duration `yaml:"delay,omitempty"`      // fixed hedge delay
+	Percentile float64       `yaml:"percentile,omitempty"` // hedge after this observed latency percentile instead
+	Budget     float64       `yaml:"budget,omitempty"`     // extra attempts per request, shared with retries
+}
+
+// CoalesceConfig lets identical concurrent GET requests share one upstream call
+type CoalesceConfig struct {
+	QueryParams []string `yaml:"query_params,omitempty"` // params that distinguish requests; all when empty
+	Scope       string   `yaml:"scope,omitempty"`        // user (default) or public
+}
+
+// FallbackConfig describes the degraded answer served when the upstream of a route fails
+type FallbackConfig struct {
+	Type       string        `yaml:"type"`                  // static, stale or service
+	StatusCode int           `yaml:"status_code,omitempty"` // static: defaults to 200
+	Body       interface{}   `yaml:"body,omitempty"`        // static: JSON body
+	MaxAge     time.Duration `yaml:"max_age,omitempty"`     // stale: how long a response may be served
+	Scope      string        `yaml:"scope,omitempty"`       // stale: user (default) or public
+	Service    string        `yaml:"service,omitempty"`     // service: alternative upstream
+	TargetPath string        `yaml:"target_path,omitempty"` // service: path on the alternative upstream
+}
+
+// BulkheadConfig limits in-flight requests to a service or route
+type BulkheadConfig struct {
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	MaxQueue      int           `yaml:"max_queue,omitempty"` // requests waiting for a slot
+	MaxWait       time.Duration `yaml:"max_wait,omitempty"`  // longest time a request may wait
+}
+
+// DiscoveryConfig selects how the instances of a service are discovered
+type DiscoveryConfig struct {
+	Type      string        `yaml:"type"`                // static, dns_a, dns_srv or file
+	Name      string        `yaml:"name,omitempty"`      // host name or SRV record to resolve
+	Port      int           `yaml:"port,omitempty"`      // port for A records, defaults to the service URL port
+	Scheme    string        `yaml:"scheme,omitempty"`    // defaults to the service URL scheme
+	Refresh   time.Duration `yaml:"refresh,omitempty"`   // re-resolve or re-read interval
+	File      string        `yaml:"file,omitempty"`      // JSON/YAML file mapping service names to URLs
+	Endpoints []string      `yaml:"endpoints,omitempty"` // static instances
+}
+
+// TLSConfig configures TLS and mutual TLS towards an upstream service
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file,omitempty"`
+	CertFile           string `yaml:"cert_file,omitempty"`
+	KeyFile            string `yaml:"key_file,omitempty"`
+	MinVersion         string `yaml:"min_version,omitempty"` // "1.2" or "1.3"
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"` // development only
 }
 
 // RouteConfig represents a route configuration
 type RouteConfig struct {
-	Path         string                 `yaml:"path"`
-	Method       string                 `yaml:"method"`
-	Mode         string                 `yaml:"mode"` // proxy, logic, graphql
-	Strategy     string                 `yaml:"strategy,omitempty"`
-	Upstream     string                 `yaml:"upstream,omitempty"`
-	TargetPath   string                 `yaml:"target_path,omitempty"`
-	AuthRequired bool                   `yaml:"auth_required"`
-	Upstreams    []UpstreamConfig       `yaml:"upstreams,omitempty"`
-	Metadata     map[string]interface{} `yaml:"metadata,omitempty"`
+	Path            string                 `yaml:"path"`
+	Method          string                 `yaml:"method"`
+	Mode            string                 `yaml:"mode"` // proxy, logic, graphql
+	Strategy        string                 `yaml:"strategy,omitempty"`
+	Upstream        string                 `yaml:"upstream,omitempty"`
+	TargetPath      string                 `yaml:"target_path,omitempty"`
+	Rewrite         *RewriteConfig         `yaml:"rewrite,omitempty"`
+	AuthRequired    bool                   `yaml:"auth_required"`
+	MaxBodySize     ByteSize               `yaml:"max_body_size,omitempty"`
+	MaxResponseSize ByteSize               `yaml:"max_response_size,omitempty"`
+	Upload          *UploadConfig          `yaml:"upload,omitempty"`
+	Upstreams       []UpstreamConfig       `yaml:"upstreams,omitempty"`
+	Compare         *CompareConfig         `yaml:"compare,omitempty"`
+	TrafficSplit    *TrafficSplitConfig    `yaml:"traffic_split,omitempty"`
+	Bulkhead        *BulkheadConfig        `yaml:"bulkhead,omitempty"`
+	Retry           *RetryConfig           `yaml:"retry,omitempty"`
+	Hedge           *HedgeConfig           `yaml:"hedge,omitempty"`
+	Coalesce        *CoalesceConfig        `yaml:"coalesce,omitempty"`
+	Fallback        *FallbackConfig        `yaml:"fallback,omitempty"`
+	Priority        string                 `yaml:"priority,omitempty"` // critical, high, normal (default) or low
+	Faults          []FaultConfig          `yaml:"faults,omitempty"`
+	Claims          *ClaimsPolicyConfig    `yaml:"claims,omitempty"` // layered over auth.claims
+	Group           string                 `yaml:"group,omitempty"`  // route_groups entry whose role rules apply
+	RequiredRoles   []string               `yaml:"required_roles,omitempty"`
+	AnyOfRoles      []string               `yaml:"any_of_roles,omitempty"`
+	NoneOfRoles     []string               `yaml:"none_of_roles,omitempty"`
+	Metadata        map[string]interface{} `yaml:"metadata,omitempty"`
+}
+
+// RouteGroupConfig holds role rules shared by the routes that name the group.
+// A route adds its required and excluded roles to the group's; its any_of_roles
+// replace the group's when set.
+type RouteGroupConfig struct {
+	RequiredRoles []string `yaml:"required_roles,omitempty"` // user must have every one
+	AnyOfRoles    []string `yaml:"any_of_roles,omitempty"`   // user must have at least one
+	NoneOfRoles   []string `yaml:"none_of_roles,omitempty"`  // user must have none
+}
+
+// RewriteConfig describes how the request path is rewritten before it is sent upstream
+type RewriteConfig struct {
+	StripPrefix string            `yaml:"strip_prefix,omitempty"`
+	Regex       string            `yaml:"regex,omitempty"`
+	Replacement string            `yaml:"replacement,omitempty"` // may reference $1 or ${name}
+	Query       map[string]string `yaml:"query,omitempty"`       // values may use {param} placeholders
+	Examples    []RewriteExample  `yaml:"examples,omitempty"`
+}
+
+// RewriteExample is checked at startup to document and guard a rewrite rule
+type RewriteExample struct {
+	Request string `yaml:"request"`
+	Expect  string `yaml:"expect"`
+}
+
+// UploadConfig validates multipart uploads while they are streamed upstream
+type UploadConfig struct {
+	AllowedTypes []string `yaml:"allowed_types,omitempty"` // sniffed from magic bytes, e.g. "image/png"
+	MaxFiles     int      `yaml:"max_files,omitempty"`
+	MaxFileSize  ByteSize `yaml:"max_file_size,omitempty"`
+}
+
+// CompareConfig enables response diffing against a candidate upstream for proxy routes
+type CompareConfig struct {
+	Candidate     string        `yaml:"candidate"`
+	CandidatePath string        `yaml:"candidate_path,omitempty"`
+	IgnoreFields  []string      `yaml:"ignore_fields,omitempty"`
+	SampleRate    float64       `yaml:"sample_rate,omitempty"`
+	Timeout       time.Duration `yaml:"timeout,omitempty"`
+}
+
+// TrafficSplitConfig splits proxy traffic across several upstream variants
+type TrafficSplitConfig struct {
+	Header   string          `yaml:"header,omitempty"` // e.g. "X-Canary"
+	Cookie   string          `yaml:"cookie,omitempty"`
+	Sticky   bool            `yaml:"sticky,omitempty"` // keep users on the same variant
+	Variants []VariantConfig `yaml:"variants"`
+}
+
+// VariantConfig represents a weighted upstream variant
+type VariantConfig struct {
+	Name       string `yaml:"name"`
+	Upstream   string `yaml:"upstream"`
+	TargetPath string `yaml:"target_path,omitempty"`
+	Weight     int    `yaml:"weight"`
+	Canary     bool   `yaml:"canary,omitempty"`
 }
 
 // UpstreamConfig represents upstream service configuration for logic mode
@@ -62,11 +284,63 @@ type UpstreamConfig struct {
 
 // AuthConfig holds authentication configuration
 type AuthConfig struct {
-	APIKeyHeader       string        `yaml:"api_key_header"`
-	JWTSecret          string        `yaml:"jwt_secret"`
-	JWTExpiration      time.Duration `yaml:"jwt_expiration"`
-	ValidationEndpoint string        `yaml:"validation_endpoint"`
-	ValidationStrategy string        `yaml:"validation_strategy"` // "service" or "local"
+	APIKeyHeader       string             `yaml:"api_key_header"`
+	JWTSecret          string             `yaml:"jwt_secret"` // rejected: set JWT_SECRET_KEY or jwt_secret_file instead
+	JWTSecretFile      string             `yaml:"jwt_secret_file,omitempty"`
+	JWTExpiration      time.Duration      `yaml:"jwt_expiration"`
+	ValidationEndpoint string             `yaml:"validation_endpoint"`
+	ValidationStrategy string             `yaml:"validation_strategy"` // "service", "local" or "hybrid"
+	LogoutEndpoint     string             `yaml:"logout_endpoint"`     // purges the token from the validation cache
+	ValidationCache    TokenCacheConfig   `yaml:"validation_cache"`
+	JWKS               *JWKSConfig        `yaml:"jwks,omitempty"`         // keys for RS256, ES256 and EdDSA tokens
+	SigningKeys        []SigningKeyConfig `yaml:"signing_keys,omitempty"` // HMAC key ring, replaces jwt_secret
+	Claims             ClaimsPolicyConfig `yaml:"claims"`
+	JWTSecretFromYAML  bool               `yaml:"-"` // jwt_secret was written in the config file rather than the environment
+}
+
+// AdminConfig protects the runtime management API. Its key is separate from the
+// API keys of ordinary clients and is read from the environment or a file; the API
+// is not served when no key is set.
+type AdminConfig struct {
+	APIKey     string `yaml:"-"`                      // ADMIN_API_KEY, never read from the config file
+	APIKeyFile string `yaml:"api_key_file,omitempty"` // file holding the key, or ADMIN_API_KEY_FILE
+	Header     string `yaml:"header,omitempty"`       // defaults to X-Admin-Key
+}
+
+// ClaimsPolicyConfig sets which token claims are required and how strictly time
+// claims are checked for locally validated tokens
+type ClaimsPolicyConfig struct {
+	Issuer         string            `yaml:"issuer,omitempty"`          // required iss
+	Audiences      []string          `yaml:"audiences,omitempty"`       // aud must include one of these
+	RequireNBF     bool              `yaml:"require_nbf,omitempty"`     // nbf must be present
+	RequireIAT     bool              `yaml:"require_iat,omitempty"`     // iat must be present
+	MaxAge         time.Duration     `yaml:"max_age,omitempty"`         // oldest accepted iat
+	ClockSkew      time.Duration     `yaml:"clock_skew,omitempty"`      // leeway on exp, nbf and iat
+	RequiredClaims map[string]string `yaml:"required_claims,omitempty"` // claim -> value; an empty value only requires presence
+}
+
+// SigningKeyConfig names one HMAC key of the key ring. Secrets are read from a
+// file or an environment variable, never written in the configuration itself.
+type SigningKeyConfig struct {
+	ID         string    `yaml:"id"`
+	Status     string    `yaml:"status"`                // active, verify_only or retired
+	SecretFile string    `yaml:"secret_file,omitempty"` // file holding the secret
+	SecretEnv  string    `yaml:"secret_env,omitempty"`  // environment variable holding the secret
+	GraceUntil time.Time `yaml:"grace_until,omitempty"` // verify_only: last moment tokens are accepted, unset means no limit
+}
+
+// JWKSConfig points at the JSON Web Key Set holding the auth service's public keys
+type JWKSConfig struct {
+	URL             string        `yaml:"url,omitempty"`
+	File            string        `yaml:"file,omitempty"`             // local JWKS document, for offline use
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty"` // defaults to 10m
+}
+
+// TokenCacheConfig caches auth service token validations
+type TokenCacheConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	TTL        time.Duration `yaml:"ttl"`         // maximum age of a cached validation, never beyond the token's exp
+	MaxEntries int           `yaml:"max_entries"` // bound on cached tokens
 }
 
 // StrategyConfig holds strategy-specific configuration
@@ -84,13 +358,16 @@ type StrategyConfig struct {
 
 // Config holds all configuration for the API Gateway
 type Config struct {
-	Server     ServerConfig              `yaml:"server"`
-	CORS       CORSConfig                `yaml:"cors"`
-	Logging    LoggingConfig             `yaml:"logging"`
-	Services   map[string]ServiceConfig  `yaml:"services"`
-	Routes     []RouteConfig             `yaml:"routes"`
-	Auth       AuthConfig                `yaml:"auth"`
-	Strategies map[string]StrategyConfig `yaml:"strategies"`
+	Server         ServerConfig                `yaml:"server"`
+	CORS           CORSConfig                  `yaml:"cors"`
+	Logging        LoggingConfig               `yaml:"logging"`
+	Services       map[string]ServiceConfig    `yaml:"services"`
+	Routes         []RouteConfig               `yaml:"routes"`
+	RouteGroups    map[string]RouteGroupConfig `yaml:"route_groups"`
+	Auth           AuthConfig                  `yaml:"auth"`
+	Strategies     map[string]StrategyConfig   `yaml:"strategies"`
+	FaultInjection FaultInjectionConfig        `yaml:"fault_injection"`
+	Admin          AdminConfig                 `yaml:"admin"`
 
 	// Legacy fields for backward compatibility
 	AnalyticsServiceURL         string
@@ -148,6 +425,41 @@ func (c *Config) populateDefaults() {
 	if c.Server.WriteTimeout == 0 {
 		c.Server.WriteTimeout = getDurationEnv("WRITE_TIMEOUT", "30s")
 	}
+	if c.Server.MaxBodySize == 0 {
+		c.Server.MaxBodySize = getByteSizeEnv("MAX_BODY_SIZE", "10MB")
+	}
+	if c.Server.MaxHeaderBytes == 0 {
+		c.Server.MaxHeaderBytes = getByteSizeEnv("MAX_HEADER_BYTES", "1MB")
+	}
+	if c.Server.MaxJSONDepth == 0 {
+		c.Server.MaxJSONDepth = getEnvAsInt("MAX_JSON_DEPTH", 32)
+	}
+	if c.Server.TrailingSlash == "" {
+		c.Server.TrailingSlash = getEnv("TRAILING_SLASH", "strip")
+	}
+	if !c.Server.LoadShedding.Enabled {
+		c.Server.LoadShedding.Enabled = getEnvAsBool("LOAD_SHEDDING_ENABLED", false)
+	}
+	if c.Server.LoadShedding.MaxInFlight == 0 {
+		c.Server.LoadShedding.MaxInFlight = getEnvAsInt("LOAD_SHEDDING_MAX_IN_FLIGHT", 1000)
+	}
+	if c.Server.LoadShedding.MaxGoroutines == 0 {
+		c.Server.LoadShedding.MaxGoroutines = getEnvAsInt("LOAD_SHEDDING_MAX_GOROUTINES", 10000)
+	}
+	if c.Server.LoadShedding.TargetLatency == 0 {
+		c.Server.LoadShedding.TargetLatency = getDurationEnv("LOAD_SHEDDING_TARGET_LATENCY", "2s")
+	}
+	if c.Server.Shutdown.DrainDelay == 0 {
+		c.Server.Shutdown.DrainDelay = getDurationEnv("SHUTDOWN_DRAIN_DELAY", "5s")
+	}
+	if c.Server.Shutdown.Timeout == 0 {
+		c.Server.Shutdown.Timeout = getDurationEnv("SHUTDOWN_TIMEOUT", "30s")
+	}
+
+	// Fault injection stays off unless switched on here or through the admin API
+	if !c.FaultInjection.Enabled {
+		c.FaultInjection.Enabled = getEnvAsBool("FAULT_INJECTION_ENABLED", false)
+	}
 
 	// CORS defaults
 	if len(c.CORS.AllowedMethods) == 0 {
@@ -194,12 +506,34 @@ func (c *Config) populateDefaults() {
 		}
 	}
 
+	// Bound buffered upstream responses unless a service sets its own limit
+	defaultMaxResponseSize := getByteSizeEnv("MAX_RESPONSE_SIZE", "50MB")
+	for name, service := range c.Services {
+		if service.MaxResponseSize == 0 {
+			service.MaxResponseSize = defaultMaxResponseSize
+			c.Services[name] = service
+		}
+	}
+
+	// Admin API defaults
+	c.Admin.APIKey = getEnv("ADMIN_API_KEY", "")
+	if c.Admin.APIKeyFile == "" {
+		c.Admin.APIKeyFile = getEnv("ADMIN_API_KEY_FILE", "")
+	}
+	if c.Admin.Header == "" {
+		c.Admin.Header = getEnv("ADMIN_API_KEY_HEADER", "X-Admin-Key")
+	}
+
 	// Auth defaults
 	if c.Auth.APIKeyHeader == "" {
 		c.Auth.APIKeyHeader = getEnv("API_KEY_HEADER", "X-API-Key")
 	}
+	c.Auth.JWTSecretFromYAML = c.Auth.JWTSecret != ""
 	if c.Auth.JWTSecret == "" {
-		c.Auth.JWTSecret = getEnv("JWT_SECRET_KEY", "test-jwt-secret-key-for-development-only-32-chars-minimum")
+		c.Auth.JWTSecret = getEnv("JWT_SECRET_KEY", "")
+	}
+	if c.Auth.JWTSecretFile == "" {
+		c.Auth.JWTSecretFile = getEnv("JWT_SECRET_FILE", "")
 	}
 	if c.Auth.JWTExpiration == 0 {
 		c.Auth.JWTExpiration = getDurationEnv("JWT_EXPIRATION", "24h")
@@ -210,6 +544,38 @@ func (c *Config) populateDefaults() {
 	if c.Auth.ValidationStrategy == "" {
 		c.Auth.ValidationStrategy = getEnv("JWT_VALIDATION_STRATEGY", "service")
 	}
+	if c.Auth.JWKS == nil {
+		if url, file := getEnv("JWKS_URL", ""), getEnv("JWKS_FILE", ""); url != "" || file != "" {
+			c.Auth.JWKS = &JWKSConfig{URL: url, File: file}
+		}
+	}
+	if c.Auth.Claims.Issuer == "" {
+		c.Auth.Claims.Issuer = getEnv("JWT_ISSUER", "")
+	}
+	if len(c.Auth.Claims.Audiences) == 0 {
+		if audiences := getEnv("JWT_AUDIENCES", ""); audiences != "" {
+			for _, audience := range strings.Split(audiences, ",") {
+				if audience = strings.TrimSpace(audience); audience != "" {
+					c.Auth.Claims.Audiences = append(c.Auth.Claims.Audiences, audience)
+				}
+			}
+		}
+	}
+	if c.Auth.Claims.ClockSkew == 0 {
+		c.Auth.Claims.ClockSkew = getDurationEnv("JWT_CLOCK_SKEW", "30s")
+	}
+	if c.Auth.LogoutEndpoint == "" {
+		c.Auth.LogoutEndpoint = getEnv("JWT_LOGOUT_ENDPOINT", "/api/v1/auth/logout")
+	}
+	if !c.Auth.ValidationCache.Enabled {
+		c.Auth.ValidationCache.Enabled = getEnvAsBool("TOKEN_CACHE_ENABLED", false)
+	}
+	if c.Auth.ValidationCache.TTL == 0 {
+		c.Auth.ValidationCache.TTL = getDurationEnv("TOKEN_CACHE_TTL", "5m")
+	}
+	if c.Auth.ValidationCache.MaxEntries == 0 {
+		c.Auth.ValidationCache.MaxEntries = getEnvAsInt("TOKEN_CACHE_MAX_ENTRIES", 10000)
+	}
 
 	// Legacy fields for backward compatibility
 	c.Port = fmt.Sprintf("%d", c.Server.Port)
@@ -281,3 +647,43 @@ func getDurationEnv(name string, defaultVal string) time.Duration {
 	}
 	return 30 * time.Second
 }
+
+// getByteSizeEnv gets an environment variable as byte size with a default value
+func getByteSizeEnv(name string, defaultVal string) ByteSize {
+	if size, err := parseByteSize(getEnv(name, defaultVal)); err == nil {
+		return ByteSize(size)
+	}
+	size, _ := parseByteSize(defaultVal)
+	return ByteSize(size)
+}
+
+// parseByteSize parses a size with an optional B, KB, MB or GB suffix
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier = unit.factor
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid byte size: %q", value)
+	}
+	return number * multiplier, nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1048576", 1048576, false},
+		{"512B", 512, false},
+		{"512KB", 512 << 10, false},
+		{"10MB", 10 << 20, false},
+		{"1GB", 1 << 30, false},
+		{" 10 mb ", 10 << 20, false},
+		{"-1", 0, true},
+		{"ten", 0, true},
+		{"1.5MB", 0, true},
+		{"10TB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseByteSize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseByteSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseByteSize(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
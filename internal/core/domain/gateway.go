@@ -3,7 +3,6 @@ package domain
 import (
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -75,16 +74,18 @@ const (
 
 // RequestContext represents the context of an incoming request
 type RequestContext struct {
-	RequestID   string                 `json:"request_id"`
-	Method      string                 `json:"method"`
-	Path        string                 `json:"path"`
-	Headers     map[string]string      `json:"headers"`
-	Query       map[string]string      `json:"query"`
-	Body        interface{}            `json:"body,omitempty"`
-	User        *User                  `json:"user,omitempty"`
-	Route       *Route                 `json:"route,omitempty"`
-	StartTime   time.Time              `json:"start_time"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	RequestID     string                 `json:"request_id"`
+	Method        string                 `json:"method"`
+	Path          string                 `json:"path"`
+	Headers       map[string]string      `json:"headers"`
+	Query         map[string]string      `json:"query"`
+	RawQuery      string                 `json:"raw_query,omitempty"` // query string as received, repeated parameters included
+	Body          interface{}            `json:"body,omitempty"`
+	ContentLength int64                  `json:"content_length,omitempty"`
+	User          *User                  `json:"user,omitempty"`
+	Route         *Route                 `json:"route,omitempty"`
+	StartTime     time.Time              `json:"start_time"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // User represents an authenticated user
@@ -202,63 +203,39 @@ func (u *User) HasAnyRole(roles []string) bool {
 	return false
 }
 
-// MatchesPath checks if a request path matches the route path pattern
-func (r *Route) MatchesPath(requestPath string) bool {
-	// Simple path matching with support for path parameters
-	routeParts := strings.Split(strings.Trim(r.Path, "/"), "/")
-	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
-	
-	if len(routeParts) != len(requestParts) {
-		return false
-	}
-	
-	for i, routePart := range routeParts {
-		if strings.HasPrefix(routePart, "{") && strings.HasSuffix(routePart, "}") {
-			// This is a path parameter, skip validation
-			continue
-		}
-		if routePart != requestParts[i] {
-			return false
+// MissingRoles returns the roles from the list the user does not have
+func (u *User) MissingRoles(roles []string) []string {
+	var missing []string
+	for _, role := range roles {
+		if !u.HasRole(role) {
+			missing = append(missing, role)
 		}
 	}
-	
-	return true
+	return missing
+}
+
+// MatchesPath checks if a request path matches the route path pattern
+func (r *Route) MatchesPath(requestPath string) bool {
+	_, matched := MatchPath(r.Path, requestPath)
+	return matched
 }
 
 // ExtractPathParams extracts path parameters from a request path
 func (r *Route) ExtractPathParams(requestPath string) map[string]string {
-	params := make(map[string]string)
-	
-	routeParts := strings.Split(strings.Trim(r.Path, "/"), "/")
-	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
-	
-	if len(routeParts) != len(requestParts) {
-		return params
-	}
-	
-	for i, routePart := range routeParts {
-		if strings.HasPrefix(routePart, "{") && strings.HasSuffix(routePart, "}") {
-			paramName := strings.Trim(routePart, "{}")
-			params[paramName] = requestParts[i]
-		}
+	params, matched := MatchPath(r.Path, requestPath)
+	if !matched {
+		return make(map[string]string)
 	}
-	
 	return params
 }
 
 // BuildTargetURL builds the target URL for proxy requests
 func (r *Route) BuildTargetURL(baseURL, requestPath string) string {
-	if r.TargetPath == "" {
-		return baseURL + requestPath
-	}
-	
-	// Replace path parameters in target path
-	targetPath := r.TargetPath
-	params := r.ExtractPathParams(requestPath)
-	
-	for key, value := range params {
-		targetPath = strings.ReplaceAll(targetPath, "{"+key+"}", value)
+	rule := RewriteRule{TargetPath: r.TargetPath}
+	targetPath, err := rule.rewritePath(requestPath, r.ExtractPathParams(requestPath))
+	if err != nil {
+		// Never forward a path that failed validation
+		return baseURL
 	}
-	
 	return baseURL + targetPath
-}
\ No newline at end of file
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrResponseTooLarge is returned when an upstream response exceeds the configured maximum size
+var ErrResponseTooLarge = errors.New("upstream response exceeds maximum size")
+
+// ReadLimited reads the whole reader, failing with ErrResponseTooLarge once more than
+// limit bytes have been read. A limit of zero or less disables the check.
+func ReadLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+
+	// Read one extra byte to detect bodies that exceed the limit
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w (limit %d bytes)", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsafePath is returned when a request path or path parameter could change which upstream resource is hit
+var ErrUnsafePath = errors.New("unsafe path")
+
+// Trailing slash policies applied to incoming paths before route matching
+const (
+	TrailingSlashStrip    = "strip"
+	TrailingSlashRedirect = "redirect"
+	TrailingSlashPreserve = "preserve"
+)
+
+// NormalizePath validates an escaped request path and returns its cleaned, decoded form.
+// Encoded slashes and double encoding are rejected, duplicate slashes are collapsed and
+// dot segments are resolved. A trailing slash is kept so callers can apply their policy.
+func NormalizePath(escapedPath string) (string, error) {
+	lower := strings.ToLower(escapedPath)
+	if strings.Contains(lower, "%2f") || strings.Contains(lower, "%5c") {
+		return "", fmt.Errorf("%w: encoded path separator", ErrUnsafePath)
+	}
+
+	decoded, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsafePath, err)
+	}
+	if isEncoded(decoded) {
+		return "", fmt.Errorf("%w: double-encoded path", ErrUnsafePath)
+	}
+	if strings.ContainsAny(decoded, "\\\x00") {
+		return "", fmt.Errorf("%w: invalid character in path", ErrUnsafePath)
+	}
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(decoded, "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			if len(segments) == 0 {
+				return "", fmt.Errorf("%w: path escapes root", ErrUnsafePath)
+			}
+			segments = segments[:len(segments)-1]
+		default:
+			segments = append(segments, segment)
+		}
+	}
+
+	normalized := "/" + strings.Join(segments, "/")
+	if len(segments) > 0 && strings.HasSuffix(decoded, "/") {
+		normalized += "/"
+	}
+	return normalized, nil
+}
+
+// EscapePathSegment validates a single path parameter value and escapes it for use in a URL path
+func EscapePathSegment(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", fmt.Errorf("%w: empty path parameter", ErrUnsafePath)
+	case value == "." || value == "..":
+		return "", fmt.Errorf("%w: dot segment %q", ErrUnsafePath, value)
+	case strings.ContainsAny(value, "/\\\x00"):
+		return "", fmt.Errorf("%w: separator in path parameter %q", ErrUnsafePath, value)
+	case isEncoded(value):
+		return "", fmt.Errorf("%w: double-encoded path parameter %q", ErrUnsafePath, value)
+	}
+	return url.PathEscape(value), nil
+}
+
+// EscapePath escapes every segment of a decoded path, keeping its slashes
+func EscapePath(path string) (string, error) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		escaped, err := EscapePathSegment(segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = escaped
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// ExpandPathTemplate substitutes path parameters into a path template, escaping each value
+func ExpandPathTemplate(template string, params map[string]string) (string, error) {
+	escaped := make(map[string]string, len(params))
+	for name, value := range params {
+		var err error
+		if name == WildcardParam {
+			if !strings.Contains(template, WildcardParam) {
+				continue
+			}
+			escaped[name], err = EscapePath(value)
+		} else if strings.Contains(template, "{"+name+"}") {
+			escaped[name], err = EscapePathSegment(value)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return ExpandTemplate(template, escaped), nil
+}
+
+// isEncoded reports whether a decoded value still contains percent-encoded octets
+func isEncoded(value string) bool {
+	for i := 0; i+2 < len(value); i++ {
+		if value[i] == '%' && isHex(value[i+1]) && isHex(value[i+2]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHex reports whether a byte is a hexadecimal digit
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
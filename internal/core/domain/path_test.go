@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"clean path", "/api/v1/plants", "/api/v1/plants", false},
+		{"duplicate slashes", "/api//v1///plants", "/api/v1/plants", false},
+		{"dot segments", "/api/./v1/x/../plants", "/api/v1/plants", false},
+		{"trailing slash kept", "/api/v1/plants/", "/api/v1/plants/", false},
+		{"root", "/", "/", false},
+		{"decoded characters", "/files/a%20b", "/files/a b", false},
+		{"escapes root", "/../etc/passwd", "", true},
+		{"encoded slash", "/files/a%2Fb", "", true},
+		{"encoded backslash", "/files/a%5cb", "", true},
+		{"double encoding", "/files/a%2541", "", true},
+		{"null byte", "/files/a%00b", "", true},
+		{"invalid escape", "/files/%zz", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsafePath) {
+					t.Errorf("NormalizePath(%q) error = %v, want ErrUnsafePath", tt.path, err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapePathSegment(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "42", "42", false},
+		{"space and question mark", "a b?", "a%20b%3F", false},
+		{"empty", "", "", true},
+		{"dot", ".", "", true},
+		{"dot dot", "..", "", true},
+		{"slash", "a/b", "", true},
+		{"backslash", "a\\b", "", true},
+		{"already encoded", "a%2Fb", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EscapePathSegment(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EscapePathSegment(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EscapePathSegment(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
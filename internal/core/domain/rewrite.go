@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// WildcardParam is the parameter name under which the remainder matched by a trailing "*" is stored
+const WildcardParam = "*"
+
+// RewriteRule describes how a request path matched by a route maps to an upstream path
+type RewriteRule struct {
+	// TargetPath is a template using {name} for path parameters and * for the wildcard remainder
+	TargetPath string
+	// StripPrefix removes a prefix from the request path when no TargetPath is set
+	StripPrefix string
+	// Regex is matched against the request path; Replacement may reference $1 or ${name}
+	Regex       string
+	Replacement string
+	// Query sets upstream query parameters from templates using the same placeholders as TargetPath
+	Query map[string]string
+}
+
+// RewriteExample documents the expected upstream path for a sample request path
+type RewriteExample struct {
+	Request string
+	Expect  string
+}
+
+// regexCache keeps compiled rewrite expressions, as rules are evaluated on every request
+var regexCache sync.Map
+
+// MatchPath matches a request path against a route pattern and returns its parameters.
+// Segments like {id} capture a single segment and a trailing * captures the remainder.
+func MatchPath(routePath, requestPath string) (map[string]string, bool) {
+	routeParts := splitPath(routePath)
+	requestParts := splitPath(requestPath)
+	params := make(map[string]string)
+
+	wildcard := len(routeParts) > 0 && routeParts[len(routeParts)-1] == WildcardParam
+	if wildcard {
+		routeParts = routeParts[:len(routeParts)-1]
+		if len(requestParts) < len(routeParts) {
+			return nil, false
+		}
+	} else if len(routeParts) != len(requestParts) {
+		return nil, false
+	}
+
+	for i, routePart := range routeParts {
+		if isParamSegment(routePart) {
+			params[strings.Trim(routePart, "{}")] = requestParts[i]
+			continue
+		}
+		if routePart != requestParts[i] {
+			return nil, false
+		}
+	}
+
+	if wildcard {
+		params[WildcardParam] = strings.Join(requestParts[len(routeParts):], "/")
+	}
+	return params, true
+}
+
+// ExpandTemplate replaces {name} placeholders with the given parameters and a "*"
+// segment with the wildcard remainder. Only a whole "*" segment is the wildcard, and
+// an empty remainder drops that segment alone, leaving the rest of the template as is.
+func ExpandTemplate(template string, params map[string]string) string {
+	remainder, hasWildcard := params[WildcardParam]
+	segments := strings.Split(template, "/")
+	expanded := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == WildcardParam && hasWildcard {
+			if remainder != "" {
+				expanded = append(expanded, remainder)
+			}
+			continue
+		}
+		expanded = append(expanded, expandPlaceholders(segment, params))
+	}
+
+	result := strings.Join(expanded, "/")
+	if result == "" && strings.HasPrefix(template, "/") {
+		return "/"
+	}
+	return result
+}
+
+// expandPlaceholders substitutes the {name} placeholders of one template segment in a
+// single pass, so substituted values are never expanded again. Unknown names are kept.
+func expandPlaceholders(segment string, params map[string]string) string {
+	var builder strings.Builder
+	for {
+		start := strings.Index(segment, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(segment[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+		name := segment[start+1 : end]
+		value, ok := params[name]
+		if !ok || name == WildcardParam {
+			value = segment[start : end+1]
+		}
+		builder.WriteString(segment[:start])
+		builder.WriteString(value)
+		segment = segment[end+1:]
+	}
+	builder.WriteString(segment)
+	return builder.String()
+}
+
+// Rewrite maps a request path and raw query to the upstream path and raw query.
+// Without query templates the raw query is passed through byte for byte.
+func (rule RewriteRule) Rewrite(routePath, requestPath, rawQuery string) (string, string, error) {
+	params, _ := MatchPath(routePath, requestPath)
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	targetPath, err := rule.rewritePath(requestPath, params)
+	if err != nil {
+		return "", "", err
+	}
+	if len(rule.Query) == 0 {
+		return targetPath, rawQuery, nil
+	}
+
+	// Malformed pairs are dropped, as url.URL.Query does
+	upstreamQuery, _ := url.ParseQuery(rawQuery)
+	for key, template := range rule.Query {
+		value := ExpandTemplate(template, params)
+		if value == "" {
+			upstreamQuery.Del(key)
+			continue
+		}
+		upstreamQuery.Set(key, value)
+	}
+
+	return targetPath, upstreamQuery.Encode(), nil
+}
+
+// rewritePath applies the regex, template or prefix rule, in that order of precedence.
+// Substituted values are escaped segment by segment so they cannot change the upstream resource.
+func (rule RewriteRule) rewritePath(requestPath string, params map[string]string) (string, error) {
+	if rule.Regex != "" {
+		re, err := compileRewriteRegex(rule.Regex)
+		if err != nil {
+			return "", err
+		}
+		if match := re.FindStringSubmatchIndex(requestPath); match != nil {
+			for i, name := range re.SubexpNames() {
+				if name != "" && match[2*i] >= 0 {
+					params[name] = requestPath[match[2*i]:match[2*i+1]]
+				}
+			}
+			return EscapePath(string(re.ExpandString(nil, rule.Replacement, requestPath, match)))
+		}
+	}
+
+	if rule.TargetPath != "" {
+		return ExpandPathTemplate(rule.TargetPath, params)
+	}
+
+	if rule.StripPrefix != "" && strings.HasPrefix(requestPath, rule.StripPrefix) {
+		stripped := strings.TrimPrefix(requestPath, rule.StripPrefix)
+		if !strings.HasPrefix(stripped, "/") {
+			stripped = "/" + stripped
+		}
+		return EscapePath(stripped)
+	}
+
+	return EscapePath(requestPath)
+}
+
+// Validate compiles the rule and checks it against its documented examples
+func (rule RewriteRule) Validate(routePath string, examples []RewriteExample) error {
+	if rule.Regex != "" {
+		if _, err := compileRewriteRegex(rule.Regex); err != nil {
+			return err
+		}
+	}
+
+	for _, example := range examples {
+		requestURL, err := url.Parse(example.Request)
+		if err != nil {
+			return fmt.Errorf("invalid example request %q: %w", example.Request, err)
+		}
+		if _, ok := MatchPath(routePath, requestURL.Path); !ok {
+			return fmt.Errorf("example request %q does not match route %s", example.Request, routePath)
+		}
+
+		path, rawQuery, err := rule.Rewrite(routePath, requestURL.Path, requestURL.RawQuery)
+		if err != nil {
+			return fmt.Errorf("example request %q: %w", example.Request, err)
+		}
+		got := path
+		if rawQuery != "" {
+			got += "?" + rawQuery
+		}
+		if got != example.Expect {
+			return fmt.Errorf("example request %q rewrote to %q, expected %q", example.Request, got, example.Expect)
+		}
+	}
+	return nil
+}
+
+// compileRewriteRegex compiles a rewrite expression once and caches it
+func compileRewriteRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rewrite regex %q: %w", pattern, err)
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// splitPath splits a path into its non-empty segments
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return []string{}
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// isParamSegment reports whether a route segment is a {name} placeholder
+func isParamSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		routePath   string
+		requestPath string
+		params      map[string]string
+		ok          bool
+	}{
+		{"literal", "/api/v1/plants", "/api/v1/plants", map[string]string{}, true},
+		{"named param", "/api/v1/plants/{id}", "/api/v1/plants/42", map[string]string{"id": "42"}, true},
+		{"extra segment", "/api/v1/plants/{id}", "/api/v1/plants/42/photos", nil, false},
+		{"literal mismatch", "/api/v1/plants/{id}", "/api/v1/users/42", nil, false},
+		{"wildcard remainder", "/files/*", "/files/a/b/c", map[string]string{"*": "a/b/c"}, true},
+		{"empty wildcard", "/files/*", "/files", map[string]string{"*": ""}, true},
+		{"param and wildcard", "/users/{id}/*", "/users/7/roles/admin", map[string]string{"id": "7", "*": "roles/admin"}, true},
+		{"too short for wildcard", "/users/{id}/*", "/users", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, ok := MatchPath(tt.routePath, tt.requestPath)
+			if ok != tt.ok {
+				t.Fatalf("MatchPath(%q, %q) ok = %v, want %v", tt.routePath, tt.requestPath, ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.routePath, tt.requestPath, params, tt.params)
+			}
+		})
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+		want     string
+	}{
+		{"named params", "/plants/{plant_id}/photos/{photo_id}", map[string]string{"plant_id": "1", "photo_id": "2"}, "/plants/1/photos/2"},
+		{"unknown param kept", "/plants/{plant_id}", map[string]string{}, "/plants/{plant_id}"},
+		{"wildcard segment", "/files/*", map[string]string{"*": "a/b"}, "/files/a/b"},
+		{"empty wildcard drops its segment", "/files/*", map[string]string{"*": ""}, "/files"},
+		{"empty wildcard at root", "/*", map[string]string{"*": ""}, "/"},
+		{"wildcard in the middle", "/files/*/meta", map[string]string{"*": ""}, "/files/meta"},
+		{"star inside a segment is literal", "/v*/items/*", map[string]string{"*": "x"}, "/v*/items/x"},
+		{"star without wildcard param", "/files/*", map[string]string{}, "/files/*"},
+		{"double slash preserved", "/a//{id}", map[string]string{"id": "1"}, "/a//1"},
+		{"values are not expanded again", "/{a}/{b}", map[string]string{"a": "{b}", "b": "x"}, "/{b}/x"},
+		{"query value", "{controller_id}", map[string]string{"controller_id": "ctrl-7"}, "ctrl-7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandTemplate(tt.template, tt.params); got != tt.want {
+				t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteRuleRewrite(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        RewriteRule
+		routePath   string
+		requestPath string
+		rawQuery    string
+		wantPath    string
+		wantQuery   string
+		wantErr     bool
+	}{
+		{
+			name:        "no rule keeps the path",
+			routePath:   "/api/v1/plants/{id}",
+			requestPath: "/api/v1/plants/42",
+			wantPath:    "/api/v1/plants/42",
+		},
+		{
+			name:        "target path template",
+			rule:        RewriteRule{TargetPath: "/plants/{id}"},
+			routePath:   "/api/v1/plants/{id}",
+			requestPath: "/api/v1/plants/42",
+			wantPath:    "/plants/42",
+		},
+		{
+			name:        "target path wildcard",
+			rule:        RewriteRule{TargetPath: "/storage/*"},
+			routePath:   "/api/v1/files/*",
+			requestPath: "/api/v1/files/a/b.png",
+			wantPath:    "/storage/a/b.png",
+		},
+		{
+			name:        "strip prefix",
+			rule:        RewriteRule{StripPrefix: "/api/v1"},
+			routePath:   "/api/v1/*",
+			requestPath: "/api/v1/plants/42",
+			wantPath:    "/plants/42",
+		},
+		{
+			name:        "regex with named group",
+			rule:        RewriteRule{Regex: "^/api/v1/analytics/latest/(?P<controller>[^/]+)$", Replacement: "/api/v2/measurements/${controller}/latest"},
+			routePath:   "/api/v1/analytics/latest/{controller_id}",
+			requestPath: "/api/v1/analytics/latest/ctrl-7",
+			wantPath:    "/api/v2/measurements/ctrl-7/latest",
+		},
+		{
+			name:        "regex takes precedence over target path",
+			rule:        RewriteRule{TargetPath: "/ignored/{id}", Regex: "^/old/(\\d+)$", Replacement: "/new/$1"},
+			routePath:   "/old/{id}",
+			requestPath: "/old/5",
+			wantPath:    "/new/5",
+		},
+		{
+			name:        "raw query passes through unchanged",
+			rule:        RewriteRule{TargetPath: "/plants/{id}"},
+			routePath:   "/api/v1/plants/{id}",
+			requestPath: "/api/v1/plants/42",
+			rawQuery:    "z=1&a=2&a=%41",
+			wantPath:    "/plants/42",
+			wantQuery:   "z=1&a=2&a=%41",
+		},
+		{
+			name:        "query templates set and remove values",
+			rule:        RewriteRule{Query: map[string]string{"source": "gateway", "id": "{id}", "debug": ""}},
+			routePath:   "/api/v1/plants/{id}",
+			requestPath: "/api/v1/plants/42",
+			rawQuery:    "unit=c&debug=1",
+			wantPath:    "/api/v1/plants/42",
+			wantQuery:   "id=42&source=gateway&unit=c",
+		},
+		{
+			name:        "unsafe param value",
+			rule:        RewriteRule{TargetPath: "/plants/{id}"},
+			routePath:   "/api/v1/plants/{id}",
+			requestPath: "/api/v1/plants/..",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid regex",
+			rule:        RewriteRule{Regex: "(", Replacement: "/x"},
+			routePath:   "/x",
+			requestPath: "/x",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, rawQuery, err := tt.rule.Rewrite(tt.routePath, tt.requestPath, tt.rawQuery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rewrite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if path != tt.wantPath || rawQuery != tt.wantQuery {
+				t.Errorf("Rewrite() = %q, %q, want %q, %q", path, rawQuery, tt.wantPath, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestRewriteRuleValidate(t *testing.T) {
+	rule := RewriteRule{
+		Regex:       "^/api/v1/analytics/latest/(?P<controller>[^/]+)$",
+		Replacement: "/api/v2/measurements/${controller}/latest",
+		Query:       map[string]string{"source": "gateway"},
+	}
+	routePath := "/api/v1/analytics/latest/{controller_id}"
+
+	tests := []struct {
+		name     string
+		examples []RewriteExample
+		wantErr  bool
+	}{
+		{"no examples", nil, false},
+		{"matching example", []RewriteExample{{Request: "/api/v1/analytics/latest/ctrl-7?unit=c", Expect: "/api/v2/measurements/ctrl-7/latest?source=gateway&unit=c"}}, false},
+		{"wrong expectation", []RewriteExample{{Request: "/api/v1/analytics/latest/ctrl-7", Expect: "/api/v2/measurements/ctrl-8/latest?source=gateway"}}, true},
+		{"request outside the route", []RewriteExample{{Request: "/api/v1/plants/1", Expect: "/api/v1/plants/1"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := rule.Validate(routePath, tt.examples); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "fmt"
+
+// UploadError describes an upload rejected while it was being streamed upstream
+type UploadError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("upload rejected (%s): %s", e.Code, e.Message)
+}
+
+// NewUploadError creates a new upload error
+func NewUploadError(statusCode int, code, message string) *UploadError {
+	return &UploadError{
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+	}
+}
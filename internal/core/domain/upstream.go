@@ -0,0 +1,42 @@
+package domain
+
+import "fmt"
+
+// UpstreamTLSError describes a failed TLS handshake with an upstream service
+type UpstreamTLSError struct {
+	Service string
+	Err     error
+}
+
+// Error implements the error interface
+func (e *UpstreamTLSError) Error() string {
+	return fmt.Sprintf("TLS handshake with upstream %s failed: %v", e.Service, e.Err)
+}
+
+// Unwrap returns the underlying handshake error
+func (e *UpstreamTLSError) Unwrap() error {
+	return e.Err
+}
+
+// BulkheadError describes a request rejected because a bulkhead was full
+type BulkheadError struct {
+	Scope  string
+	Name   string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *BulkheadError) Error() string {
+	return fmt.Sprintf("%s bulkhead %s rejected request: %s", e.Scope, e.Name, e.Reason)
+}
+
+// ConcurrencyLimitError describes a request shed because a service's adaptive limit was reached
+type ConcurrencyLimitError struct {
+	Service string
+	Limit   int
+}
+
+// Error implements the error interface
+func (e *ConcurrencyLimitError) Error() string {
+	return fmt.Sprintf("upstream %s concurrency limit of %d reached", e.Service, e.Limit)
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"net/http"
+	"time"
 )
 
 // HTTPClient defines the port for HTTP client operations
@@ -19,15 +20,187 @@ type RouteHandler interface {
 
 // RouteConfig represents the configuration for a specific route
 type RouteConfig struct {
-	Path         string
-	Method       string
-	Mode         string
-	Strategy     string
-	Upstream     string
-	TargetPath   string
-	AuthRequired bool
-	Upstreams    []UpstreamConfig
-	Metadata     map[string]interface{}
+	Path            string
+	Method          string
+	Mode            string
+	Strategy        string
+	Upstream        string
+	TargetPath      string
+	Rewrite         *RewriteConfig
+	AuthRequired    bool
+	MaxBodySize     int64
+	MaxResponseSize int64
+	Upload          *UploadPolicy
+	Upstreams       []UpstreamConfig
+	Compare         *CompareConfig
+	TrafficSplit    *TrafficSplitConfig
+	Bulkhead        *BulkheadConfig
+	Retry           *RetryPolicy
+	Hedge           *HedgePolicy
+	Coalesce        *CoalesceConfig
+	Fallback        *FallbackConfig
+	Priority        string
+	Faults          []FaultRule
+	Claims          *ClaimsPolicy
+	Access          *AccessPolicy
+	Metadata        map[string]interface{}
+}
+
+// ClaimsPolicy sets which token claims are required and how strictly time claims are checked
+type ClaimsPolicy struct {
+	Issuer         string
+	Audiences      []string
+	RequireNBF     bool
+	RequireIAT     bool
+	MaxAge         time.Duration
+	ClockSkew      time.Duration
+	RequiredClaims map[string]string
+}
+
+// AccessPolicy lists the roles a user needs to call a route, after its group's
+// rules have been merged in
+type AccessPolicy struct {
+	Group         string
+	RequiredRoles []string
+	AnyOfRoles    []string
+	NoneOfRoles   []string
+}
+
+// RetryPolicy retries failed idempotent upstream calls
+type RetryPolicy struct {
+	MaxAttempts int
+	RetryOn     []int
+	Backoff     time.Duration
+}
+
+// HedgePolicy sends a second attempt when the first one is slow to answer
+type HedgePolicy struct {
+	Delay      time.Duration
+	Percentile float64
+	Budget     float64
+}
+
+// attemptPolicyKey is the context key under which a route's attempt policies are stored
+type attemptPolicyKey struct{}
+
+// attemptPolicies groups the retry and hedge policies of a route with the route they belong to
+type attemptPolicies struct {
+	route string
+	retry *RetryPolicy
+	hedge *HedgePolicy
+}
+
+// WithAttemptPolicies attaches a route's retry and hedge policies to upstream calls made with ctx
+func WithAttemptPolicies(ctx context.Context, route string, retry *RetryPolicy, hedge *HedgePolicy) context.Context {
+	return context.WithValue(ctx, attemptPolicyKey{}, attemptPolicies{route: route, retry: retry, hedge: hedge})
+}
+
+// AttemptPoliciesFromContext returns the retry and hedge policies attached to ctx
+func AttemptPoliciesFromContext(ctx context.Context) (*RetryPolicy, *HedgePolicy) {
+	policies, _ := ctx.Value(attemptPolicyKey{}).(attemptPolicies)
+	return policies.retry, policies.hedge
+}
+
+// AttemptRouteFromContext returns the route whose attempt policies are attached to ctx
+func AttemptRouteFromContext(ctx context.Context) string {
+	policies, _ := ctx.Value(attemptPolicyKey{}).(attemptPolicies)
+	return policies.route
+}
+
+// FaultRule injects a failure into a share of the upstream calls of a route or service
+type FaultRule struct {
+	Type       string
+	Delay      time.Duration
+	Status     int
+	Bytes      int64
+	Percentage float64
+	Header     string
+}
+
+// faultScopeKey is the context key under which a route's fault rules are stored
+type faultScopeKey struct{}
+
+// FaultScope carries a route's fault rules and the client's headers to upstream calls
+type FaultScope struct {
+	Route   string
+	Rules   []FaultRule
+	Headers map[string]string
+}
+
+// WithFaultScope attaches a route's fault rules and the client's headers to upstream calls made with ctx
+func WithFaultScope(ctx context.Context, scope FaultScope) context.Context {
+	return context.WithValue(ctx, faultScopeKey{}, scope)
+}
+
+// FaultScopeFromContext returns the fault scope attached to ctx
+func FaultScopeFromContext(ctx context.Context) (FaultScope, bool) {
+	scope, ok := ctx.Value(faultScopeKey{}).(FaultScope)
+	return scope, ok
+}
+
+// CoalesceConfig describes which concurrent GET requests share one upstream call
+type CoalesceConfig struct {
+	QueryParams []string
+	Scope       string
+}
+
+// FallbackConfig describes the degraded answer served when the upstream of a route fails
+type FallbackConfig struct {
+	Type       string
+	StatusCode int
+	Body       interface{}
+	MaxAge     time.Duration
+	Scope      string
+	Service    string
+	TargetPath string
+}
+
+// BulkheadConfig limits concurrent requests, queueing the excess for a bounded time
+type BulkheadConfig struct {
+	MaxConcurrent int
+	MaxQueue      int
+	MaxWait       time.Duration
+}
+
+// RewriteConfig describes path and query rewriting applied on top of the target path
+type RewriteConfig struct {
+	StripPrefix string
+	Regex       string
+	Replacement string
+	Query       map[string]string
+}
+
+// UploadPolicy restricts the files accepted in a multipart upload
+type UploadPolicy struct {
+	AllowedTypes []string
+	MaxFiles     int
+	MaxFileSize  int64
+}
+
+// CompareConfig describes how a proxy route is diffed against a candidate upstream
+type CompareConfig struct {
+	Candidate     string
+	CandidatePath string
+	IgnoreFields  []string
+	SampleRate    float64
+	Timeout       time.Duration
+}
+
+// TrafficSplitConfig describes how a route splits traffic across upstream variants
+type TrafficSplitConfig struct {
+	Header   string
+	Cookie   string
+	Sticky   bool
+	Variants []TrafficVariant
+}
+
+// TrafficVariant represents a weighted upstream variant of a route
+type TrafficVariant struct {
+	Name       string
+	Upstream   string
+	TargetPath string
+	Weight     int
+	Canary     bool
 }
 
 // UpstreamConfig represents configuration for upstream services
@@ -41,6 +214,7 @@ type UpstreamConfig struct {
 type AuthService interface {
 	ValidateAPIKey(ctx context.Context, apiKey string) (bool, error)
 	ValidateJWT(ctx context.Context, token string) (*UserInfo, error)
+	ValidateJWTWithPolicy(ctx context.Context, token string, routePolicy *ClaimsPolicy) (*UserInfo, error)
 	GenerateJWT(ctx context.Context, userInfo *UserInfo) (string, error)
 }
 
@@ -86,9 +260,22 @@ type StrategyParams struct {
 
 // ServiceInfo contains information about a backend service
 type ServiceInfo struct {
-	Name    string
-	URL     string
-	Timeout string
+	Name            string
+	URL             string
+	Timeout         string
+	MaxResponseSize int64
+	Endpoints       []string
+	Transport       http.RoundTripper
+	Critical        bool
+}
+
+// ResponseLimit returns the maximum buffered response size for a call to this service
+// on the given route; the route limit takes precedence over the service limit
+func (si ServiceInfo) ResponseLimit(routeConfig RouteConfig) int64 {
+	if routeConfig.MaxResponseSize > 0 {
+		return routeConfig.MaxResponseSize
+	}
+	return si.MaxResponseSize
 }
 
 // ServiceOrchestrator defines the port for orchestrating multiple service calls
@@ -107,6 +294,12 @@ type ServiceCall struct {
 	Parallel   bool
 }
 
+// ServiceDiscovery defines the port for resolving the instances of an upstream service
+type ServiceDiscovery interface {
+	// Endpoints returns the base URLs of the known instances of a service
+	Endpoints(serviceName string) []string
+}
+
 // HealthChecker defines the port for health checking operations
 type HealthChecker interface {
 	CheckHealth(ctx context.Context, serviceName string) (HealthStatus, error)
@@ -132,6 +325,7 @@ type MetricsCollector interface {
 type ConfigProvider interface {
 	GetRouteConfig(path string, method string) (*RouteConfig, bool)
 	GetServiceConfig(serviceName string) (*ServiceInfo, bool)
+	ListServices() []string
 	GetStrategyConfig(strategyName string) (map[string]interface{}, bool)
 	ReloadConfig() error
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Reasons an access decision gives for its outcome
+const (
+	AccessAllowed        = "allowed"
+	AccessExcludedRole   = "excluded_role"
+	AccessMissingRoles   = "missing_required_roles"
+	AccessNoAcceptedRole = "no_accepted_role"
+)
+
+// problemContentType is the media type of RFC 7807 problem details
+const problemContentType = "application/problem+json"
+
+// AccessDecision is the outcome of checking a user's roles against a route's access policy
+type AccessDecision struct {
+	Allowed bool
+	Reason  string
+	Roles   []string // the roles that decided a denial
+}
+
+// evaluateAccess checks a user against a route's access policy. Excluded roles
+// are checked first so a negation always wins, then required roles, then the
+// accepted ones.
+func evaluateAccess(user *domain.User, policy *ports.AccessPolicy) AccessDecision {
+	if policy == nil {
+		return AccessDecision{Allowed: true, Reason: AccessAllowed}
+	}
+	if user == nil {
+		user = &domain.User{}
+	}
+
+	var excluded []string
+	for _, role := range policy.NoneOfRoles {
+		if user.HasRole(role) {
+			excluded = append(excluded, role)
+		}
+	}
+	if len(excluded) > 0 {
+		return AccessDecision{Reason: AccessExcludedRole, Roles: excluded}
+	}
+	if missing := user.MissingRoles(policy.RequiredRoles); len(missing) > 0 {
+		return AccessDecision{Reason: AccessMissingRoles, Roles: missing}
+	}
+	if len(policy.AnyOfRoles) > 0 && !user.HasAnyRole(policy.AnyOfRoles) {
+		return AccessDecision{Reason: AccessNoAcceptedRole, Roles: policy.AnyOfRoles}
+	}
+	return AccessDecision{Allowed: true, Reason: AccessAllowed}
+}
+
+// forbiddenResponse is the problem details answer for a denied request. The roles
+// that decided the denial are logged, not returned to the caller.
+func forbiddenResponse(reqCtx *domain.RequestContext) *domain.Response {
+	return &domain.Response{
+		StatusCode: http.StatusForbidden,
+		Headers:    map[string]string{"Content-Type": problemContentType},
+		Body: map[string]interface{}{
+			"type":       "about:blank",
+			"title":      http.StatusText(http.StatusForbidden),
+			"status":     http.StatusForbidden,
+			"detail":     "Your roles do not allow this request",
+			"instance":   reqCtx.Path,
+			"request_id": reqCtx.RequestID,
+		},
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestEvaluateAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  []string
+		noUser bool
+		policy *ports.AccessPolicy
+		want   AccessDecision
+	}{
+		{
+			name: "no policy",
+			want: AccessDecision{Allowed: true, Reason: AccessAllowed},
+		},
+		{
+			name:   "required roles present",
+			roles:  []string{"admin", "user"},
+			policy: &ports.AccessPolicy{RequiredRoles: []string{"admin"}},
+			want:   AccessDecision{Allowed: true, Reason: AccessAllowed},
+		},
+		{
+			name:   "required roles missing",
+			roles:  []string{"user"},
+			policy: &ports.AccessPolicy{RequiredRoles: []string{"admin", "auditor"}},
+			want:   AccessDecision{Reason: AccessMissingRoles, Roles: []string{"admin", "auditor"}},
+		},
+		{
+			name:   "one accepted role",
+			roles:  []string{"technician"},
+			policy: &ports.AccessPolicy{AnyOfRoles: []string{"admin", "technician"}},
+			want:   AccessDecision{Allowed: true, Reason: AccessAllowed},
+		},
+		{
+			name:   "no accepted role",
+			roles:  []string{"user"},
+			policy: &ports.AccessPolicy{AnyOfRoles: []string{"admin", "technician"}},
+			want:   AccessDecision{Reason: AccessNoAcceptedRole, Roles: []string{"admin", "technician"}},
+		},
+		{
+			name:   "excluded role wins over required roles",
+			roles:  []string{"admin", "suspended"},
+			policy: &ports.AccessPolicy{RequiredRoles: []string{"admin"}, NoneOfRoles: []string{"suspended"}},
+			want:   AccessDecision{Reason: AccessExcludedRole, Roles: []string{"suspended"}},
+		},
+		{
+			name:   "required checked before accepted",
+			roles:  []string{"technician"},
+			policy: &ports.AccessPolicy{RequiredRoles: []string{"admin"}, AnyOfRoles: []string{"technician"}},
+			want:   AccessDecision{Reason: AccessMissingRoles, Roles: []string{"admin"}},
+		},
+		{
+			name:   "missing user has no roles",
+			noUser: true,
+			policy: &ports.AccessPolicy{RequiredRoles: []string{"admin"}},
+			want:   AccessDecision{Reason: AccessMissingRoles, Roles: []string{"admin"}},
+		},
+		{
+			name:   "missing user passes exclusions only",
+			noUser: true,
+			policy: &ports.AccessPolicy{NoneOfRoles: []string{"suspended"}},
+			want:   AccessDecision{Allowed: true, Reason: AccessAllowed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user *domain.User
+			if !tt.noUser {
+				user = &domain.User{ID: "u1", Roles: tt.roles}
+			}
+			if got := evaluateAccess(user, tt.policy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluateAccess() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForbiddenResponse(t *testing.T) {
+	response := forbiddenResponse(&domain.RequestContext{RequestID: "req-1", Path: "/api/v1/users/7"})
+	if response.StatusCode != 403 {
+		t.Errorf("status = %d, want 403", response.StatusCode)
+	}
+	if response.Headers["Content-Type"] != problemContentType {
+		t.Errorf("content type = %q, want %q", response.Headers["Content-Type"], problemContentType)
+	}
+}
+
+// adminRoute is a config provider holding one admin-only route whose upstream is unknown
+type adminRoute struct{}
+
+func (adminRoute) GetRouteConfig(path string, method string) (*ports.RouteConfig, bool) {
+	return &ports.RouteConfig{
+		Path:         path,
+		Method:       method,
+		Mode:         "proxy",
+		Upstream:     "missing",
+		AuthRequired: true,
+		Access:       &ports.AccessPolicy{Group: "admin", RequiredRoles: []string{"admin"}},
+	}, true
+}
+func (adminRoute) GetServiceConfig(string) (*ports.ServiceInfo, bool)      { return nil, false }
+func (adminRoute) ListServices() []string                                  { return nil }
+func (adminRoute) GetStrategyConfig(string) (map[string]interface{}, bool) { return nil, false }
+func (adminRoute) ReloadConfig() error                                     { return nil }
+
+// TestAccessUsesServiceValidatedRoles runs the role rules against the roles the auth
+// service returned, in service mode, where the gateway cannot read the token itself
+func TestAccessUsesServiceValidatedRoles(t *testing.T) {
+	tests := []struct {
+		name      string
+		roles     []string
+		forbidden bool
+	}{
+		{"auth service grants admin", []string{"admin"}, false},
+		{"auth service grants viewer", []string{"viewer"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestGatewayService(&localTokens{}, false, adminRoute{})
+			reqCtx := &domain.RequestContext{
+				RequestID: "r1",
+				Method:    "DELETE",
+				Path:      "/api/v1/users/42",
+				Headers:   map[string]string{"authorization": "Bearer foreign-token"},
+				User:      &domain.User{ID: "u1", Roles: tt.roles},
+			}
+
+			resp, err := gs.ProcessRequest(context.Background(), reqCtx)
+			if err != nil {
+				t.Fatalf("ProcessRequest() error = %v", err)
+			}
+			if resp.StatusCode == http.StatusUnauthorized {
+				t.Fatal("a service-validated user was sent back to local token validation")
+			}
+			if forbidden := resp.StatusCode == http.StatusForbidden; forbidden != tt.forbidden {
+				t.Errorf("status = %d, want forbidden %v", resp.StatusCode, tt.forbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Bulkhead scopes and rejection reasons reported in errors and metrics
+const (
+	BulkheadScopeService = "service"
+	BulkheadScopeRoute   = "route"
+
+	BulkheadQueueFull   = "queue_full"
+	BulkheadWaitTimeout = "wait_timeout"
+)
+
+// defaultBulkheadMaxWait applies when a bulkhead has a queue but no max_wait
+const defaultBulkheadMaxWait = time.Second
+
+// Bulkhead caps the number of in-flight requests for a service or route. Requests
+// above the limit wait in a bounded queue for at most MaxWait before being rejected.
+type Bulkhead struct {
+	scope   string
+	name    string
+	config  ports.BulkheadConfig
+	slots   chan struct{}
+	waiting int64
+	metrics ports.MetricsCollector
+}
+
+// NewBulkhead creates a new bulkhead
+func NewBulkhead(scope, name string, config ports.BulkheadConfig, metrics ports.MetricsCollector) *Bulkhead {
+	if config.MaxWait <= 0 {
+		config.MaxWait = defaultBulkheadMaxWait
+	}
+	return &Bulkhead{
+		scope:   scope,
+		name:    name,
+		config:  config,
+		slots:   make(chan struct{}, config.MaxConcurrent),
+		metrics: metrics,
+	}
+}
+
+// Config returns the limits the bulkhead was created with
+func (b *Bulkhead) Config() ports.BulkheadConfig {
+	return b.config
+}
+
+// Acquire takes a slot, waiting in the queue if needed. The returned function
+// releases the slot and must be called exactly once.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		b.recordUtilisation()
+		return b.releaseFunc(), nil
+	default:
+	}
+
+	if atomic.AddInt64(&b.waiting, 1) > int64(b.config.MaxQueue) {
+		atomic.AddInt64(&b.waiting, -1)
+		return nil, b.reject(BulkheadQueueFull)
+	}
+	b.recordUtilisation()
+	defer func() {
+		atomic.AddInt64(&b.waiting, -1)
+		b.recordUtilisation()
+	}()
+
+	timer := time.NewTimer(b.config.MaxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaseFunc(), nil
+	case <-timer.C:
+		return nil, b.reject(BulkheadWaitTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// releaseFunc returns an idempotent function that frees the acquired slot
+func (b *Bulkhead) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-b.slots
+			b.recordUtilisation()
+		})
+	}
+}
+
+// reject records a rejection and returns the matching error
+func (b *Bulkhead) reject(reason string) error {
+	b.metrics.IncrementCounter("gateway_bulkhead_rejections_total", map[string]string{
+		"scope":  b.scope,
+		"name":   b.name,
+		"reason": reason,
+	})
+	return &domain.BulkheadError{Scope: b.scope, Name: b.name, Reason: reason}
+}
+
+// recordUtilisation publishes the current in-flight and queued counts
+func (b *Bulkhead) recordUtilisation() {
+	labels := map[string]string{"scope": b.scope, "name": b.name}
+	b.metrics.SetGauge("gateway_bulkhead_in_flight", float64(len(b.slots)), labels)
+	b.metrics.SetGauge("gateway_bulkhead_queued", float64(atomic.LoadInt64(&b.waiting)), labels)
+	b.metrics.SetGauge("gateway_bulkhead_utilisation", float64(len(b.slots))/float64(b.config.MaxConcurrent), labels)
+}
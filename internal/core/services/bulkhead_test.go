@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestBulkheadRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     ports.BulkheadConfig
+		wantReason string
+	}{
+		{"no queue", ports.BulkheadConfig{MaxConcurrent: 1}, BulkheadQueueFull},
+		{"queue wait runs out", ports.BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, MaxWait: 10 * time.Millisecond}, BulkheadWaitTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := metrics.NewCollector()
+			bulkhead := NewBulkhead(BulkheadScopeRoute, "GET /api/v1/plants", tt.config, collector)
+			release, err := bulkhead.Acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer release()
+
+			_, err = bulkhead.Acquire(context.Background())
+			var bulkheadErr *domain.BulkheadError
+			if !errors.As(err, &bulkheadErr) || bulkheadErr.Reason != tt.wantReason {
+				t.Fatalf("Acquire() error = %v, want %s", err, tt.wantReason)
+			}
+
+			var rejections float64
+			for _, counter := range collector.Snapshot().Counters {
+				if counter.Name == "gateway_bulkhead_rejections_total" && counter.Labels["reason"] == tt.wantReason {
+					rejections += counter.Value
+				}
+			}
+			if rejections != 1 {
+				t.Errorf("rejections = %v, want 1", rejections)
+			}
+		})
+	}
+}
+
+func TestBulkheadQueuedRequestGetsReleasedSlot(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadScopeService, "plants", ports.BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Second}, metrics.NewCollector())
+	release, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		queuedRelease, err := bulkhead.Acquire(context.Background())
+		if err == nil {
+			queuedRelease()
+		}
+		acquired <- err
+	}()
+
+	// A third request finds the single queue place taken
+	for deadline := time.Now().Add(time.Second); atomic.LoadInt64(&bulkhead.waiting) != 1; {
+		if time.Now().After(deadline) {
+			t.Fatal("second request never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var bulkheadErr *domain.BulkheadError
+	if _, err := bulkhead.Acquire(context.Background()); !errors.As(err, &bulkheadErr) || bulkheadErr.Reason != BulkheadQueueFull {
+		t.Errorf("third Acquire() error = %v, want %s", err, BulkheadQueueFull)
+	}
+
+	// Releasing twice frees one slot, which goes to the queued request
+	release()
+	release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("queued Acquire() error = %v", err)
+	}
+	if len(bulkhead.slots) != 0 {
+		t.Errorf("in flight = %d after every release, want 0", len(bulkhead.slots))
+	}
+}
+
+func TestBulkheadQueuedRequestCancelled(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadScopeRoute, "GET /api/v1/plants", ports.BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Minute}, metrics.NewCollector())
+	release, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := bulkhead.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v, want the context error", err)
+	}
+	if atomic.LoadInt64(&bulkhead.waiting) != 0 {
+		t.Errorf("waiting = %d after the caller left, want 0", atomic.LoadInt64(&bulkhead.waiting))
+	}
+}
+
+func TestOverloadResponse(t *testing.T) {
+	gs := newTestGatewayService(&localTokens{}, false, nil)
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string // empty when the error is not an overload
+	}{
+		{"bulkhead", &domain.BulkheadError{Scope: BulkheadScopeRoute, Name: "GET /x", Reason: BulkheadQueueFull}, "BULKHEAD_FULL"},
+		{"wrapped bulkhead", fmt.Errorf("proxy: %w", &domain.BulkheadError{Scope: BulkheadScopeService, Name: "plants", Reason: BulkheadWaitTimeout}), "BULKHEAD_FULL"},
+		{"adaptive limit", &domain.ConcurrencyLimitError{Service: "plants", Limit: 10}, "CONCURRENCY_LIMITED"},
+		{"other error", errors.New("connection refused"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := gs.overloadResponse(&domain.RequestContext{RequestID: "r1"}, tt.err)
+			if tt.wantCode == "" {
+				if resp != nil {
+					t.Errorf("overloadResponse() = %d, want nil", resp.StatusCode)
+				}
+				return
+			}
+			if resp == nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Headers["Retry-After"] == "" {
+				t.Fatalf("overloadResponse() = %+v, want 503 with Retry-After", resp)
+			}
+			if code := resp.Body.(map[string]string)["code"]; code != tt.wantCode {
+				t.Errorf("code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
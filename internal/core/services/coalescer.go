@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Coalescing scopes decide whose identical requests may share a response
+const (
+	CoalesceScopeUser   = "user"
+	CoalesceScopePublic = "public"
+)
+
+// errCoalescedCallFailed is handed to waiters when the shared call did not return
+var errCoalescedCallFailed = errors.New("coalesced upstream call failed")
+
+// coalescedCall is an upstream call that concurrent identical requests wait on
+type coalescedCall struct {
+	done     chan struct{}
+	response *domain.Response
+	err      error
+}
+
+// Coalescer lets concurrent identical requests share the response of a single
+// upstream call. Only requests that arrive while the call is in flight share it;
+// nothing is cached once it completes.
+type Coalescer struct {
+	calls   map[string]*coalescedCall
+	mutex   sync.Mutex
+	metrics ports.MetricsCollector
+}
+
+// NewCoalescer creates a new request coalescer
+func NewCoalescer(metrics ports.MetricsCollector) *Coalescer {
+	return &Coalescer{
+		calls:   make(map[string]*coalescedCall),
+		metrics: metrics,
+	}
+}
+
+// Do runs fn for the first request with a given key and hands its result to every
+// request with the same key that arrives before it completes. The call is detached
+// from the first caller's cancellation so one client disconnecting does not fail
+// the others. shared reports whether the response came from another request.
+func (c *Coalescer) Do(ctx context.Context, key, route string, fn func(context.Context) (*domain.Response, error)) (response *domain.Response, shared bool, err error) {
+	c.mutex.Lock()
+	if call, exists := c.calls[key]; exists {
+		c.mutex.Unlock()
+		c.metrics.IncrementCounter("gateway_coalesced_requests_total", map[string]string{"route": route})
+
+		select {
+		case <-call.done:
+			return copyResponse(call.response), true, call.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+
+	call := &coalescedCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+
+	// Waiters are released even when fn panics, so they never hang on the call
+	completed := false
+	defer func() {
+		if !completed {
+			call.err = errCoalescedCallFailed
+		}
+		c.mutex.Lock()
+		delete(c.calls, key)
+		c.mutex.Unlock()
+		close(call.done)
+	}()
+
+	response, err = fn(context.WithoutCancel(ctx))
+	call.response, call.err = copyResponse(response), err
+	completed = true
+
+	return response, false, err
+}
+
+// CoalesceKey identifies requests that may share a response: the method, the path,
+// the selected query parameters and, unless the scope is public, the caller identity.
+// Every part is escaped so no value can imitate the separators of another request.
+func CoalesceKey(reqCtx *domain.RequestContext, coalesce ports.CoalesceConfig) string {
+	var key strings.Builder
+	key.WriteString(reqCtx.Method)
+	key.WriteByte(' ')
+	key.WriteString(url.QueryEscape(reqCtx.Path))
+
+	names := coalesce.QueryParams
+	if len(names) == 0 {
+		names = make([]string, 0, len(reqCtx.Query))
+		for name := range reqCtx.Query {
+			names = append(names, name)
+		}
+	}
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		if value, exists := reqCtx.Query[name]; exists {
+			key.WriteString("&" + url.QueryEscape(name) + "=" + url.QueryEscape(value))
+		}
+	}
+
+	if coalesce.Scope != CoalesceScopePublic {
+		key.WriteString("|" + url.QueryEscape(callerIdentity(reqCtx)))
+	}
+	return key.String()
+}
+
+// callerIdentity returns the authenticated user ID, or a digest of the presented
+// credentials for routes that do not authenticate
+func callerIdentity(reqCtx *domain.RequestContext) string {
+	if reqCtx.User != nil {
+		return "user:" + reqCtx.User.ID
+	}
+	credentials := reqCtx.Headers["authorization"] + "\x00" + reqCtx.Headers["x-api-key"]
+	digest := sha256.Sum256([]byte(credentials))
+	return "anonymous:" + hex.EncodeToString(digest[:8])
+}
+
+// copyResponse gives a waiting request its own headers and metadata so handlers
+// can adjust them without affecting the other requests sharing the response
+func copyResponse(response *domain.Response) *domain.Response {
+	if response == nil {
+		return nil
+	}
+	copied := *response
+	if response.Headers != nil {
+		copied.Headers = make(map[string]string, len(response.Headers))
+		for key, value := range response.Headers {
+			copied.Headers[key] = value
+		}
+	}
+	if response.Metadata != nil {
+		copied.Metadata = make(map[string]interface{}, len(response.Metadata))
+		for key, value := range response.Metadata {
+			copied.Metadata[key] = value
+		}
+	}
+	return &copied
+}
+
+// isCoalescable reports whether a request may join a coalesced call
+func isCoalescable(reqCtx *domain.RequestContext, coalesce *ports.CoalesceConfig) bool {
+	return coalesce != nil && reqCtx.Method == http.MethodGet
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestCoalesceKey(t *testing.T) {
+	alice := &domain.User{ID: "alice"}
+
+	tests := []struct {
+		name      string
+		a, b      *domain.RequestContext
+		coalesce  ports.CoalesceConfig
+		wantEqual bool
+	}{
+		{
+			name:      "same request and user",
+			a:         &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"page": "1"}, User: alice},
+			b:         &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"page": "1"}, User: alice},
+			wantEqual: true,
+		},
+		{
+			name: "different users",
+			a:    &domain.RequestContext{Method: "GET", Path: "/plants", User: alice},
+			b:    &domain.RequestContext{Method: "GET", Path: "/plants", User: &domain.User{ID: "bob"}},
+		},
+		{
+			name:      "public scope ignores the user",
+			a:         &domain.RequestContext{Method: "GET", Path: "/plants", User: alice},
+			b:         &domain.RequestContext{Method: "GET", Path: "/plants", User: &domain.User{ID: "bob"}},
+			coalesce:  ports.CoalesceConfig{Scope: CoalesceScopePublic},
+			wantEqual: true,
+		},
+		{
+			name:      "unselected query parameters are ignored",
+			a:         &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"page": "1", "ts": "1"}, User: alice},
+			b:         &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"page": "1", "ts": "2"}, User: alice},
+			coalesce:  ports.CoalesceConfig{QueryParams: []string{"page"}},
+			wantEqual: true,
+		},
+		{
+			name: "all query parameters count by default",
+			a:    &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"page": "1", "ts": "1"}, User: alice},
+			b:    &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"page": "1", "ts": "2"}, User: alice},
+		},
+		{
+			name: "query value cannot imitate another parameter",
+			a:    &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"a": "1&b=2"}, User: alice},
+			b:    &domain.RequestContext{Method: "GET", Path: "/plants", Query: map[string]string{"a": "1", "b": "2"}, User: alice},
+		},
+		{
+			name:     "path cannot imitate the caller",
+			a:        &domain.RequestContext{Method: "GET", Path: "/plants|user:bob", User: alice},
+			b:        &domain.RequestContext{Method: "GET", Path: "/plants", User: &domain.User{ID: "bob"}},
+			coalesce: ports.CoalesceConfig{},
+		},
+		{
+			name: "anonymous callers with different credentials",
+			a:    &domain.RequestContext{Method: "GET", Path: "/plants", Headers: map[string]string{"authorization": "Bearer a"}},
+			b:    &domain.RequestContext{Method: "GET", Path: "/plants", Headers: map[string]string{"authorization": "Bearer b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := CoalesceKey(tt.a, tt.coalesce), CoalesceKey(tt.b, tt.coalesce)
+			if (a == b) != tt.wantEqual {
+				t.Errorf("keys %q and %q: equal = %v, want %v", a, b, a == b, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestCoalescerSharesInFlightCall(t *testing.T) {
+	coalescer := NewCoalescer(metrics.NewCollector())
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	first := make(chan *domain.Response, 1)
+	go func() {
+		response, _, _ := coalescer.Do(context.Background(), "key", "route", func(context.Context) (*domain.Response, error) {
+			close(started)
+			<-finish
+			return &domain.Response{StatusCode: 200, Headers: map[string]string{"X-Test": "1"}}, nil
+		})
+		first <- response
+	}()
+	<-started
+
+	waiter := make(chan *domain.Response, 1)
+	go func() {
+		response, shared, err := coalescer.Do(context.Background(), "key", "route", func(context.Context) (*domain.Response, error) {
+			t.Error("waiter ran its own call")
+			return nil, nil
+		})
+		if !shared || err != nil {
+			t.Errorf("waiter shared = %v, err = %v", shared, err)
+		}
+		waiter <- response
+	}()
+
+	waitForWaiter(t, coalescer)
+	close(finish)
+
+	original, copied := <-first, <-waiter
+	if copied == nil || copied.StatusCode != 200 {
+		t.Fatalf("waiter response = %+v", copied)
+	}
+	copied.Headers["X-Test"] = "changed"
+	if original.Headers["X-Test"] != "1" {
+		t.Error("waiter shares headers with the first caller")
+	}
+}
+
+func TestCoalescerReleasesWaitersWhenCallPanics(t *testing.T) {
+	coalescer := NewCoalescer(metrics.NewCollector())
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	go func() {
+		defer func() { recover() }()
+		coalescer.Do(context.Background(), "key", "route", func(context.Context) (*domain.Response, error) {
+			close(started)
+			<-finish
+			panic("upstream handler bug")
+		})
+	}()
+	<-started
+
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := coalescer.Do(context.Background(), "key", "route", func(context.Context) (*domain.Response, error) {
+			return nil, nil
+		})
+		result <- err
+	}()
+
+	waitForWaiter(t, coalescer)
+	close(finish)
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, errCoalescedCallFailed) {
+			t.Errorf("waiter error = %v, want %v", err, errCoalescedCallFailed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released after the call panicked")
+	}
+
+	coalescer.mutex.Lock()
+	defer coalescer.mutex.Unlock()
+	if len(coalescer.calls) != 0 {
+		t.Errorf("%d calls left in flight", len(coalescer.calls))
+	}
+}
+
+// waitForWaiter waits until a second request has joined the in-flight call
+func waitForWaiter(t *testing.T, coalescer *Coalescer) {
+	t.Helper()
+	collector := coalescer.metrics.(*metrics.Collector)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, counter := range collector.Snapshot().Counters {
+			if counter.Name == "gateway_coalesced_requests_total" && counter.Value > 0 {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("waiter did not join the call")
+}
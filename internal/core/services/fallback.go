@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Fallback types a route can declare
+const (
+	FallbackStatic  = "static"
+	FallbackStale   = "stale"
+	FallbackService = "service"
+)
+
+// FallbackHeader marks responses that did not come from the route's upstream
+const FallbackHeader = "X-Gateway-Fallback"
+
+const (
+	// defaultStaleMaxAge applies when a stale fallback does not set max_age
+	defaultStaleMaxAge = 10 * time.Minute
+	// maxStaleEntries bounds the number of responses kept for stale fallbacks
+	maxStaleEntries = 1000
+)
+
+// staleEntry is a successful response kept for serving when the upstream fails
+type staleEntry struct {
+	response  *domain.Response
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// StaleStore keeps the last successful response of routes with a stale fallback
+type StaleStore struct {
+	entries map[string]staleEntry
+	mutex   sync.RWMutex
+}
+
+// NewStaleStore creates a new stale response store
+func NewStaleStore() *StaleStore {
+	return &StaleStore{entries: make(map[string]staleEntry)}
+}
+
+// Store keeps a copy of a response for at most maxAge
+func (ss *StaleStore) Store(key string, response *domain.Response, maxAge time.Duration) {
+	now := time.Now()
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if _, exists := ss.entries[key]; !exists && len(ss.entries) >= maxStaleEntries {
+		ss.evict(now)
+	}
+	ss.entries[key] = staleEntry{
+		response:  copyResponse(response),
+		storedAt:  now,
+		expiresAt: now.Add(maxAge),
+	}
+}
+
+// Load returns a copy of the stored response and its age, if it has not expired
+func (ss *StaleStore) Load(key string) (*domain.Response, time.Duration, bool) {
+	ss.mutex.RLock()
+	entry, exists := ss.entries[key]
+	ss.mutex.RUnlock()
+
+	now := time.Now()
+	if !exists || now.After(entry.expiresAt) {
+		return nil, 0, false
+	}
+	return copyResponse(entry.response), now.Sub(entry.storedAt), true
+}
+
+// evict drops expired entries, or an arbitrary one when none have expired
+func (ss *StaleStore) evict(now time.Time) {
+	for key, entry := range ss.entries {
+		if now.After(entry.expiresAt) {
+			delete(ss.entries, key)
+		}
+	}
+	if len(ss.entries) < maxStaleEntries {
+		return
+	}
+	for key := range ss.entries {
+		delete(ss.entries, key)
+		return
+	}
+}
+
+// withFallback keeps successful responses for stale fallbacks and replaces failed
+// ones with the route's degraded answer. The original failure is returned when the
+// fallback has nothing to offer.
+func (gs *GatewayService) withFallback(ctx context.Context, reqCtx *domain.RequestContext, routeConfig *ports.RouteConfig, resp *domain.Response, err error) (*domain.Response, error) {
+	fallback := routeConfig.Fallback
+	routeKey := RouteKey(routeConfig.Method, routeConfig.Path)
+
+	if !isUpstreamFailure(resp, err) {
+		if fallback.Type == FallbackStale && reqCtx.Method == http.MethodGet && resp.StatusCode < http.StatusMultipleChoices {
+			gs.staleStore.Store(gs.staleKey(reqCtx, routeKey, fallback), resp, staleMaxAge(fallback))
+		}
+		return resp, err
+	}
+	if ctx.Err() != nil {
+		return resp, err
+	}
+
+	var fallbackResp *domain.Response
+	switch fallback.Type {
+	case FallbackStatic:
+		fallbackResp = staticFallback(reqCtx.Method, fallback)
+	case FallbackStale:
+		fallbackResp = gs.staleFallback(reqCtx, routeKey, fallback)
+	case FallbackService:
+		fallbackResp = gs.serviceFallback(ctx, reqCtx, routeConfig)
+	}
+
+	labels := map[string]string{"route": routeKey, "type": fallback.Type}
+	if fallbackResp == nil {
+		gs.metrics.IncrementCounter("gateway_fallback_unavailable_total", labels)
+		return resp, err
+	}
+
+	if fallbackResp.Headers == nil {
+		fallbackResp.Headers = make(map[string]string)
+	}
+	fallbackResp.Headers[FallbackHeader] = fallback.Type
+	gs.metrics.IncrementCounter("gateway_fallback_responses_total", labels)
+
+	logFields := map[string]interface{}{
+		"request_id": reqCtx.RequestID,
+		"route":      routeKey,
+		"type":       fallback.Type,
+	}
+	if err != nil {
+		logFields["error"] = err.Error()
+	} else {
+		logFields["status_code"] = resp.StatusCode
+	}
+	gs.logger.Warn("Serving fallback response", logFields)
+	return fallbackResp, nil
+}
+
+// isUpstreamFailure reports whether a route's upstream failed to answer usefully
+func isUpstreamFailure(resp *domain.Response, err error) bool {
+	return err != nil || resp == nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// StaticFallbackAllowed reports whether a static fallback with the given status
+// may answer a request with the given method. A failed POST, PUT or DELETE must
+// not be reported as a success, so unsafe methods need a non-2xx status; a
+// status of 0 means the default 200.
+func StaticFallbackAllowed(method string, statusCode int) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return statusCode >= http.StatusMultipleChoices
+}
+
+// staticFallback returns the fixed body configured for the route, or nil when it
+// would turn a failed unsafe request into a success
+func staticFallback(method string, fallback *ports.FallbackConfig) *domain.Response {
+	if !StaticFallbackAllowed(method, fallback.StatusCode) {
+		return nil
+	}
+	statusCode := fallback.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	return &domain.Response{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       fallback.Body,
+	}
+}
+
+// staleFallback returns the last successful response for the same request
+func (gs *GatewayService) staleFallback(reqCtx *domain.RequestContext, routeKey string, fallback *ports.FallbackConfig) *domain.Response {
+	if reqCtx.Method != http.MethodGet {
+		return nil
+	}
+	resp, age, found := gs.staleStore.Load(gs.staleKey(reqCtx, routeKey, fallback))
+	if !found {
+		return nil
+	}
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]string)
+	}
+	resp.Headers["Age"] = strconv.Itoa(int(age.Seconds()))
+	return resp
+}
+
+// serviceFallback sends the request to the alternative service instead
+func (gs *GatewayService) serviceFallback(ctx context.Context, reqCtx *domain.RequestContext, routeConfig *ports.RouteConfig) *domain.Response {
+	// A streamed body has already been consumed by the failed attempt
+	if _, streamed := reqCtx.Body.(io.Reader); streamed {
+		return nil
+	}
+
+	alternative := *routeConfig
+	alternative.Upstream = routeConfig.Fallback.Service
+	if routeConfig.Fallback.TargetPath != "" {
+		alternative.TargetPath = routeConfig.Fallback.TargetPath
+		alternative.Rewrite = nil
+	}
+	alternative.TrafficSplit = nil
+	alternative.Compare = nil
+	alternative.Fallback = nil
+
+	resp, err := gs.handleProxyMode(ctx, reqCtx, alternative)
+	if isUpstreamFailure(resp, err) {
+		return nil
+	}
+	return resp
+}
+
+// staleKey identifies the stored response for a request, scoped like coalescing
+func (gs *GatewayService) staleKey(reqCtx *domain.RequestContext, routeKey string, fallback *ports.FallbackConfig) string {
+	return routeKey + "|" + CoalesceKey(reqCtx, ports.CoalesceConfig{Scope: fallback.Scope})
+}
+
+// staleMaxAge returns how long a stored response may be served
+func staleMaxAge(fallback *ports.FallbackConfig) time.Duration {
+	if fallback.MaxAge > 0 {
+		return fallback.MaxAge
+	}
+	return defaultStaleMaxAge
+}
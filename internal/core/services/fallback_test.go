@@ -0,0 +1,47 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestStaticFallback(t *testing.T) {
+	tests := []struct {
+		method     string
+		statusCode int
+		wantStatus int // 0 when no fallback may be served
+	}{
+		{"GET", 0, 200},
+		{"GET", 200, 200},
+		{"HEAD", 503, 503},
+		{"OPTIONS", 0, 200},
+		{"POST", 0, 0},
+		{"POST", 201, 0},
+		{"PUT", 204, 0},
+		{"PATCH", 299, 0},
+		{"DELETE", 200, 0},
+		{"POST", 503, 503},
+		{"DELETE", 409, 409},
+		{"PUT", 302, 302},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.method, tt.statusCode), func(t *testing.T) {
+			response := staticFallback(tt.method, &ports.FallbackConfig{Type: FallbackStatic, StatusCode: tt.statusCode})
+			if allowed := StaticFallbackAllowed(tt.method, tt.statusCode); allowed != (tt.wantStatus != 0) {
+				t.Errorf("StaticFallbackAllowed(%s, %d) = %v", tt.method, tt.statusCode, allowed)
+			}
+			if tt.wantStatus == 0 {
+				if response != nil {
+					t.Errorf("%s with status %d served a fallback with status %d", tt.method, tt.statusCode, response.StatusCode)
+				}
+				return
+			}
+			if response == nil || response.StatusCode != tt.wantStatus {
+				t.Errorf("%s with status %d served %+v, want status %d", tt.method, tt.statusCode, response, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestValidateFaultRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    ports.FaultRule
+		wantErr bool
+	}{
+		{"latency", ports.FaultRule{Type: FaultLatency, Delay: time.Second, Percentage: 10}, false},
+		{"latency without delay", ports.FaultRule{Type: FaultLatency, Percentage: 10}, true},
+		{"abort", ports.FaultRule{Type: FaultAbort, Status: 503, Percentage: 100}, false},
+		{"abort with a bad status", ports.FaultRule{Type: FaultAbort, Status: 600, Percentage: 100}, true},
+		{"reset", ports.FaultRule{Type: FaultReset, Percentage: 5}, false},
+		{"truncate", ports.FaultRule{Type: FaultTruncate, Bytes: 0, Percentage: 5}, false},
+		{"truncate with negative bytes", ports.FaultRule{Type: FaultTruncate, Bytes: -1, Percentage: 5}, true},
+		{"no percentage", ports.FaultRule{Type: FaultReset}, true},
+		{"percentage above 100", ports.FaultRule{Type: FaultReset, Percentage: 150}, true},
+		{"unknown type", ports.FaultRule{Type: "explode", Percentage: 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFaultRule(tt.rule); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFaultRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFaultInjectorSelect(t *testing.T) {
+	abort := ports.FaultRule{Type: FaultAbort, Status: 503, Percentage: 100}
+	chaosOnly := ports.FaultRule{Type: FaultReset, Percentage: 100, Header: "X-Chaos"}
+	route := FaultRouteTarget("GET /api/v1/plants")
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		disabled string
+		headers  map[string]string
+		want     []ports.FaultRule
+	}{
+		{"switched off", false, "", map[string]string{"x-chaos": "1"}, nil},
+		{"rule without a header always applies", true, "", nil, []ports.FaultRule{abort}},
+		{"header opts in", true, "", map[string]string{"x-chaos": "1"}, []ports.FaultRule{abort, chaosOnly}},
+		{"target switched off", true, route, map[string]string{"x-chaos": "1"}, nil},
+		{"another target switched off", true, FaultServiceTarget("plants"), nil, []ports.FaultRule{abort}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := metrics.NewCollector()
+			injector := NewFaultInjector(tt.enabled, collector, logger.NewLogger("error", "json", "test"))
+			if tt.disabled != "" {
+				injector.SetEnabled(tt.disabled, false)
+			}
+			got := injector.Select(route, []ports.FaultRule{abort, chaosOnly}, tt.headers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Select() = %+v, want %+v", got, tt.want)
+			}
+
+			var injected float64
+			for _, counter := range collector.Snapshot().Counters {
+				if counter.Name == "gateway_faults_injected_total" && counter.Labels["target"] == route {
+					injected += counter.Value
+				}
+			}
+			if injected != float64(len(tt.want)) {
+				t.Errorf("faults injected = %v, want %d", injected, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestFaultInjectorPercentage(t *testing.T) {
+	injector := NewFaultInjector(true, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+	rules := []ports.FaultRule{{Type: FaultReset, Percentage: 20}}
+
+	const calls = 10000
+	selected := 0
+	for i := 0; i < calls; i++ {
+		selected += len(injector.Select(FaultServiceTarget("plants"), rules, nil))
+	}
+	if share := float64(selected) / calls; share < 0.17 || share > 0.23 {
+		t.Errorf("share of calls with the fault = %.3f, want about 0.2", share)
+	}
+}
+
+func TestFaultInjectorOverrides(t *testing.T) {
+	injector := NewFaultInjector(true, metrics.NewCollector(), logger.NewLogger("error", "json", "test"))
+	injector.SetEnabled(FaultServiceTarget("plants"), false)
+	injector.SetEnabled(FaultRouteTarget("GET /a"), false)
+	injector.SetEnabled(FaultRouteTarget("GET /a"), true)
+
+	enabled, disabled := injector.State()
+	if !enabled || !reflect.DeepEqual(disabled, []string{"service:plants"}) {
+		t.Errorf("State() = %v %v, want true [service:plants]", enabled, disabled)
+	}
+
+	// Resetting switches targets back on but keeps the overall switch
+	injector.SetEnabled("", false)
+	injector.ResetOverrides()
+	if enabled, disabled := injector.State(); enabled || len(disabled) != 0 {
+		t.Errorf("State() after reset = %v %v, want false []", enabled, disabled)
+	}
+}
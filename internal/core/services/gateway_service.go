@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
@@ -19,30 +22,53 @@ type GatewayService struct {
 	strategyManager     ports.StrategyManager
 	serviceOrchestrator ports.ServiceOrchestrator
 	authService         ports.AuthService
+	localTokenCheck     bool
 	logger              ports.Logger
 	httpClient          ports.HTTPClient
 	configProvider      ports.ConfigProvider
+	metrics             ports.MetricsCollector
+	responseComparator  *ResponseComparator
+	trafficSplitter     *TrafficSplitter
+	routeBulkheads      map[string]*Bulkhead
+	bulkheadMutex       sync.Mutex
+	coalescer           *Coalescer
+	staleStore          *StaleStore
 }
 
-// NewGatewayService creates a new gateway service
+// NewGatewayService creates a new gateway service; localTokenCheck lets it verify
+// bearer tokens itself when no validated user came with the request
 func NewGatewayService(
 	strategyManager ports.StrategyManager,
 	serviceOrchestrator ports.ServiceOrchestrator,
 	authService ports.AuthService,
+	localTokenCheck bool,
 	logger ports.Logger,
 	httpClient ports.HTTPClient,
 	configProvider ports.ConfigProvider,
+	metrics ports.MetricsCollector,
 ) *GatewayService {
 	return &GatewayService{
 		strategyManager:     strategyManager,
 		serviceOrchestrator: serviceOrchestrator,
 		authService:         authService,
+		localTokenCheck:     localTokenCheck,
 		logger:              logger,
 		httpClient:          httpClient,
 		configProvider:      configProvider,
+		metrics:             metrics,
+		responseComparator:  NewResponseComparator(),
+		trafficSplitter:     NewTrafficSplitter(logger),
+		routeBulkheads:      make(map[string]*Bulkhead),
+		coalescer:           NewCoalescer(metrics),
+		staleStore:          NewStaleStore(),
 	}
 }
 
+// TrafficSplitter returns the traffic splitter used for weighted routes
+func (gs *GatewayService) TrafficSplitter() *TrafficSplitter {
+	return gs.trafficSplitter
+}
+
 // ProcessRequest processes an incoming request based on the route configuration
 func (gs *GatewayService) ProcessRequest(ctx context.Context, reqCtx *domain.RequestContext) (*domain.Response, error) {
 	// Find matching route
@@ -77,7 +103,7 @@ func (gs *GatewayService) ProcessRequest(ctx context.Context, reqCtx *domain.Req
 
 	// Handle authentication if required
 	if route.AuthRequired {
-		user, err := gs.authenticateRequest(ctx, reqCtx)
+		user, err := gs.authenticateRequest(ctx, reqCtx, routeConfig.Claims)
 		if err != nil {
 			gs.logger.Error("Authentication failed", err, map[string]interface{}{
 				"request_id": reqCtx.RequestID,
@@ -88,8 +114,91 @@ func (gs *GatewayService) ProcessRequest(ctx context.Context, reqCtx *domain.Req
 			}, nil
 		}
 		reqCtx.User = user
+
+		// Role rules are checked only once the caller is known
+		if routeConfig.Access != nil {
+			decision := evaluateAccess(user, routeConfig.Access)
+			fields := map[string]interface{}{
+				"request_id": reqCtx.RequestID,
+				"method":     reqCtx.Method,
+				"path":       reqCtx.Path,
+				"user_id":    user.ID,
+				"group":      routeConfig.Access.Group,
+				"allowed":    decision.Allowed,
+				"reason":     decision.Reason,
+			}
+			if !decision.Allowed {
+				fields["roles"] = decision.Roles
+				gs.logger.Warn("🚫 Access denied", fields)
+				return forbiddenResponse(reqCtx), nil
+			}
+			gs.logger.Info("Access granted", fields)
+		}
+	}
+
+	// Let identical concurrent requests share a single upstream call
+	if isCoalescable(reqCtx, routeConfig.Coalesce) {
+		key := CoalesceKey(reqCtx, *routeConfig.Coalesce)
+		resp, shared, err := gs.coalescer.Do(ctx, key, RouteKey(routeConfig.Method, routeConfig.Path), func(callCtx context.Context) (*domain.Response, error) {
+			return gs.serve(callCtx, reqCtx, routeConfig)
+		})
+		if shared && resp != nil {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]string)
+			}
+			resp.Headers["X-Gateway-Coalesced"] = "true"
+			gs.logger.Debug("Request coalesced with an in-flight call", map[string]interface{}{
+				"request_id": reqCtx.RequestID,
+				"path":       reqCtx.Path,
+			})
+		}
+		return resp, err
 	}
 
+	return gs.serve(ctx, reqCtx, routeConfig)
+}
+
+// serve dispatches the request and falls back to the route's degraded answer
+// when its upstream fails
+func (gs *GatewayService) serve(ctx context.Context, reqCtx *domain.RequestContext, routeConfig *ports.RouteConfig) (*domain.Response, error) {
+	resp, err := gs.dispatch(ctx, reqCtx, routeConfig)
+	if routeConfig.Fallback == nil {
+		return resp, err
+	}
+	return gs.withFallback(ctx, reqCtx, routeConfig, resp, err)
+}
+
+// dispatch applies the route's concurrency and attempt policies and hands the
+// request to the handler for its mode
+func (gs *GatewayService) dispatch(ctx context.Context, reqCtx *domain.RequestContext, routeConfig *ports.RouteConfig) (*domain.Response, error) {
+	route := reqCtx.Route
+
+	// Limit concurrent requests on the route
+	if bulkhead := gs.routeBulkhead(*routeConfig); bulkhead != nil {
+		release, err := bulkhead.Acquire(ctx)
+		if err != nil {
+			if resp := gs.overloadResponse(reqCtx, err); resp != nil {
+				return resp, nil
+			}
+			return nil, err
+		}
+		defer release()
+	}
+
+	routeKey := RouteKey(routeConfig.Method, routeConfig.Path)
+
+	// Let upstream calls of this route retry and hedge according to its policies
+	if routeConfig.Retry != nil || routeConfig.Hedge != nil {
+		ctx = ports.WithAttemptPolicies(ctx, routeKey, routeConfig.Retry, routeConfig.Hedge)
+	}
+
+	// Let upstream calls see the route's fault rules and the client's headers
+	ctx = ports.WithFaultScope(ctx, ports.FaultScope{
+		Route:   routeKey,
+		Rules:   routeConfig.Faults,
+		Headers: reqCtx.Headers,
+	})
+
 	// Route based on mode
 	switch route.Mode {
 	case domain.ProxyMode:
@@ -106,8 +215,67 @@ func (gs *GatewayService) ProcessRequest(ctx context.Context, reqCtx *domain.Req
 	}
 }
 
-// authenticateRequest handles request authentication
-func (gs *GatewayService) authenticateRequest(ctx context.Context, reqCtx *domain.RequestContext) (*domain.User, error) {
+// routeBulkhead returns the bulkhead of a route, recreating it when its limits change
+func (gs *GatewayService) routeBulkhead(routeConfig ports.RouteConfig) *Bulkhead {
+	if routeConfig.Bulkhead == nil || routeConfig.Bulkhead.MaxConcurrent <= 0 {
+		return nil
+	}
+
+	routeKey := RouteKey(routeConfig.Method, routeConfig.Path)
+	gs.bulkheadMutex.Lock()
+	defer gs.bulkheadMutex.Unlock()
+
+	bulkhead, exists := gs.routeBulkheads[routeKey]
+	if !exists || bulkhead.Config() != *routeConfig.Bulkhead {
+		bulkhead = NewBulkhead(BulkheadScopeRoute, routeKey, *routeConfig.Bulkhead, gs.metrics)
+		gs.routeBulkheads[routeKey] = bulkhead
+	}
+	return bulkhead
+}
+
+// overloadResponse maps a bulkhead or adaptive limit rejection to 503 Service Unavailable
+func (gs *GatewayService) overloadResponse(reqCtx *domain.RequestContext, err error) *domain.Response {
+	var limitErr *domain.ConcurrencyLimitError
+	if errors.As(err, &limitErr) {
+		return &domain.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Headers:    map[string]string{"Retry-After": "1"},
+			Body: map[string]string{
+				"error": "Service temporarily overloaded",
+				"code":  "CONCURRENCY_LIMITED",
+			},
+		}
+	}
+
+	var bulkheadErr *domain.BulkheadError
+	if !errors.As(err, &bulkheadErr) {
+		return nil
+	}
+
+	gs.logger.Warn("Request rejected by bulkhead", map[string]interface{}{
+		"request_id": reqCtx.RequestID,
+		"scope":      bulkheadErr.Scope,
+		"name":       bulkheadErr.Name,
+		"reason":     bulkheadErr.Reason,
+	})
+	return &domain.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Headers:    map[string]string{"Retry-After": "1"},
+		Body: map[string]string{
+			"error": "Service temporarily overloaded",
+			"code":  "BULKHEAD_FULL",
+		},
+	}
+}
+
+// authenticateRequest handles request authentication. A user already validated by the
+// JWT middleware is trusted as is; otherwise JWTs are checked locally, against the
+// route's claims policy, only when the gateway validates tokens itself
+func (gs *GatewayService) authenticateRequest(ctx context.Context, reqCtx *domain.RequestContext, claims *ports.ClaimsPolicy) (*domain.User, error) {
+	if reqCtx.User != nil {
+		return reqCtx.User, nil
+	}
+
 	gs.logger.Info("🔐 Authenticating request", map[string]interface{}{
 		"request_id":    reqCtx.RequestID,
 		"method":        reqCtx.Method,
@@ -162,9 +330,9 @@ func (gs *GatewayService) authenticateRequest(ctx context.Context, reqCtx *domai
 			"request_id":    reqCtx.RequestID,
 			"header_prefix": authHeader[:min(20, len(authHeader))],
 		})
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " && gs.localTokenCheck {
 			token := authHeader[7:]
-			userInfo, err := gs.authService.ValidateJWT(ctx, token)
+			userInfo, err := gs.authService.ValidateJWTWithPolicy(ctx, token, claims)
 			if err != nil {
 				return nil, err
 			}
@@ -187,6 +355,10 @@ func (gs *GatewayService) authenticateRequest(ctx context.Context, reqCtx *domai
 
 // handleProxyMode handles proxy mode requests
 func (gs *GatewayService) handleProxyMode(ctx context.Context, reqCtx *domain.RequestContext, routeConfig ports.RouteConfig) (*domain.Response, error) {
+	if routeConfig.TrafficSplit != nil {
+		return gs.handleSplitProxyMode(ctx, reqCtx, routeConfig)
+	}
+
 	gs.logger.Info("🔀 Handling proxy mode", map[string]interface{}{
 		"request_id":  reqCtx.RequestID,
 		"upstream":    routeConfig.Upstream,
@@ -240,6 +412,33 @@ func (gs *GatewayService) handleProxyMode(ctx context.Context, reqCtx *domain.Re
 
 	result, err := gs.strategyManager.ExecuteStrategy(ctx, strategyName, strategyParams)
 	if err != nil {
+		// Streamed uploads can be rejected while they are being forwarded
+		if resp := gs.uploadErrorResponse(err); resp != nil {
+			gs.logger.Warn("Upload rejected while streaming", map[string]interface{}{
+				"request_id":  reqCtx.RequestID,
+				"upstream":    routeConfig.Upstream,
+				"status_code": resp.StatusCode,
+				"error":       err.Error(),
+			})
+			return resp, nil
+		}
+		if errors.Is(err, domain.ErrUnsafePath) {
+			return gs.unsafePathResponse(reqCtx, err), nil
+		}
+		if resp := gs.overloadResponse(reqCtx, err); resp != nil {
+			return resp, nil
+		}
+		var tlsErr *domain.UpstreamTLSError
+		if errors.As(err, &tlsErr) {
+			return &domain.Response{
+				StatusCode: http.StatusBadGateway,
+				Body: map[string]string{
+					"error": "Upstream TLS handshake failed",
+					"code":  "UPSTREAM_TLS_ERROR",
+				},
+			}, nil
+		}
+
 		gs.logger.Error("Proxy strategy execution failed", err, map[string]interface{}{
 			"request_id": reqCtx.RequestID,
 			"upstream":   routeConfig.Upstream,
@@ -256,12 +455,33 @@ func (gs *GatewayService) handleProxyMode(ctx context.Context, reqCtx *domain.Re
 	})
 
 	if httpResp, ok := result.(*http.Response); ok {
-		resp, convertErr := gs.convertHTTPResponse(httpResp)
+		resp, convertErr := gs.convertHTTPResponse(httpResp, serviceInfo.ResponseLimit(routeConfig))
+		if errors.Is(convertErr, domain.ErrResponseTooLarge) {
+			gs.logger.Error("Upstream response too large", convertErr, map[string]interface{}{
+				"request_id": reqCtx.RequestID,
+				"upstream":   routeConfig.Upstream,
+				"max_bytes":  serviceInfo.ResponseLimit(routeConfig),
+			})
+			return &domain.Response{
+				StatusCode: http.StatusBadGateway,
+				Body: map[string]string{
+					"error": "Upstream response too large",
+					"code":  "UPSTREAM_RESPONSE_TOO_LARGE",
+				},
+			}, nil
+		}
 		gs.logger.Info("📦 Response converted", map[string]interface{}{
 			"request_id":  reqCtx.RequestID,
 			"status_code": resp.StatusCode,
 			"has_error":   convertErr != nil,
 		})
+
+		// Diff against the candidate upstream in the background; the client
+		// always receives the primary response
+		if convertErr == nil && routeConfig.Compare != nil {
+			gs.startComparison(reqCtx, routeConfig, resp)
+		}
+
 		return resp, convertErr
 	}
 
@@ -275,6 +495,238 @@ func (gs *GatewayService) handleProxyMode(ctx context.Context, reqCtx *domain.Re
 	}, nil
 }
 
+// uploadErrorResponse maps errors raised while streaming a request body to a 4xx response
+func (gs *GatewayService) uploadErrorResponse(err error) *domain.Response {
+	var uploadErr *domain.UploadError
+	if errors.As(err, &uploadErr) {
+		return &domain.Response{
+			StatusCode: uploadErr.StatusCode,
+			Body: map[string]string{
+				"error": uploadErr.Message,
+				"code":  uploadErr.Code,
+			},
+		}
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &domain.Response{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Headers:    map[string]string{"Connection": "close"},
+			Body: map[string]string{
+				"error": "Request body too large",
+				"code":  "BODY_TOO_LARGE",
+			},
+		}
+	}
+	return nil
+}
+
+// unsafePathResponse rejects a request whose path parameters cannot be forwarded safely
+func (gs *GatewayService) unsafePathResponse(reqCtx *domain.RequestContext, err error) *domain.Response {
+	gs.logger.Warn("Unsafe path parameter rejected", map[string]interface{}{
+		"request_id": reqCtx.RequestID,
+		"path":       reqCtx.Path,
+		"error":      err.Error(),
+	})
+	return &domain.Response{
+		StatusCode: http.StatusBadRequest,
+		Body: map[string]string{
+			"error": "Invalid path parameter",
+			"code":  "INVALID_PATH",
+		},
+	}
+}
+
+// handleSplitProxyMode selects an upstream variant and proxies the request to it
+func (gs *GatewayService) handleSplitProxyMode(ctx context.Context, reqCtx *domain.RequestContext, routeConfig ports.RouteConfig) (*domain.Response, error) {
+	userID := ""
+	if reqCtx.User != nil {
+		userID = reqCtx.User.ID
+	}
+
+	routeKey := RouteKey(routeConfig.Method, routeConfig.Path)
+	variant, reason := gs.trafficSplitter.SelectVariant(routeKey, routeConfig.TrafficSplit, reqCtx.Headers, userID)
+
+	gs.logger.Info("🔀 Traffic split variant selected", map[string]interface{}{
+		"request_id": reqCtx.RequestID,
+		"route":      routeKey,
+		"variant":    variant.Name,
+		"upstream":   variant.Upstream,
+		"reason":     reason,
+	})
+
+	routeConfig.TrafficSplit = nil
+	routeConfig.Upstream = variant.Upstream
+	if variant.TargetPath != "" {
+		routeConfig.TargetPath = variant.TargetPath
+	}
+
+	start := time.Now()
+	resp, err := gs.handleProxyMode(ctx, reqCtx, routeConfig)
+
+	statusCode := http.StatusInternalServerError
+	if err == nil && resp != nil {
+		statusCode = resp.StatusCode
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]string)
+		}
+		resp.Headers["X-Gateway-Variant"] = variant.Name
+	}
+
+	if gs.metrics != nil {
+		labels := map[string]string{
+			"route":    routeKey,
+			"variant":  variant.Name,
+			"upstream": variant.Upstream,
+		}
+		gs.metrics.RecordHistogram("gateway_variant_latency_ms", float64(time.Since(start).Milliseconds()), labels)
+		gs.metrics.IncrementCounter("gateway_variant_requests_total", labels)
+		if statusCode >= http.StatusInternalServerError {
+			gs.metrics.IncrementCounter("gateway_variant_errors_total", labels)
+		}
+	}
+
+	return resp, err
+}
+
+// startComparison replays the request against the candidate upstream in the
+// background when a comparison slot is free
+func (gs *GatewayService) startComparison(reqCtx *domain.RequestContext, routeConfig ports.RouteConfig, primary *domain.Response) {
+	// Only replay safe methods so the candidate never applies side effects twice
+	if reqCtx.Method != http.MethodGet && reqCtx.Method != http.MethodHead {
+		gs.logger.Debug("Skipping response comparison for unsafe method", map[string]interface{}{
+			"request_id": reqCtx.RequestID,
+			"method":     reqCtx.Method,
+		})
+		return
+	}
+
+	release, ok := gs.responseComparator.TryAcquire()
+	if !ok {
+		gs.metrics.IncrementCounter("gateway_compare_skipped_total", map[string]string{
+			"route":     RouteKey(routeConfig.Method, routeConfig.Path),
+			"candidate": routeConfig.Compare.Candidate,
+		})
+		return
+	}
+	go func() {
+		defer release()
+		gs.compareWithCandidate(reqCtx, routeConfig, primary)
+	}()
+}
+
+// compareWithCandidate replays the request against the candidate upstream and diffs the responses
+func (gs *GatewayService) compareWithCandidate(reqCtx *domain.RequestContext, routeConfig ports.RouteConfig, primary *domain.Response) {
+	compare := routeConfig.Compare
+
+	serviceInfo, found := gs.configProvider.GetServiceConfig(compare.Candidate)
+	if !found {
+		gs.logger.Warn("Compare candidate service not configured", map[string]interface{}{
+			"request_id": reqCtx.RequestID,
+			"candidate":  compare.Candidate,
+		})
+		return
+	}
+
+	timeout := compare.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	candidateRoute := routeConfig
+	candidateRoute.Upstream = compare.Candidate
+	candidateRoute.Compare = nil
+	if compare.CandidatePath != "" {
+		candidateRoute.TargetPath = compare.CandidatePath
+	}
+
+	strategyName := routeConfig.Strategy
+	if strategyName == "" {
+		strategyName = "proxy"
+	}
+
+	strategyParams := ports.StrategyParams{
+		Request:     gs.createHTTPRequestFromContext(reqCtx),
+		RouteConfig: candidateRoute,
+		Services: map[string]ports.ServiceInfo{
+			compare.Candidate: *serviceInfo,
+		},
+		UserInfo:   gs.convertUser(reqCtx.User),
+		HTTPClient: gs.httpClient,
+		Logger:     gs.logger,
+	}
+
+	result, err := gs.strategyManager.ExecuteStrategy(ctx, strategyName, strategyParams)
+	if err != nil {
+		gs.logger.Warn("Compare candidate request failed", map[string]interface{}{
+			"request_id": reqCtx.RequestID,
+			"route_path": routeConfig.Path,
+			"candidate":  compare.Candidate,
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	httpResp, ok := result.(*http.Response)
+	if !ok {
+		return
+	}
+	defer httpResp.Body.Close()
+
+	candidateBody, err := domain.ReadLimited(httpResp.Body, serviceInfo.ResponseLimit(routeConfig))
+	if err != nil {
+		gs.logger.Warn("Failed to read compare candidate response", map[string]interface{}{
+			"request_id": reqCtx.RequestID,
+			"candidate":  compare.Candidate,
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	comparison := gs.responseComparator.Compare(
+		primary.StatusCode, responseBodyBytes(primary.Body),
+		httpResp.StatusCode, candidateBody,
+		compare.IgnoreFields,
+	)
+
+	fields := map[string]interface{}{
+		"request_id":       reqCtx.RequestID,
+		"route_path":       routeConfig.Path,
+		"primary":          routeConfig.Upstream,
+		"candidate":        compare.Candidate,
+		"primary_status":   comparison.PrimaryStatus,
+		"candidate_status": comparison.CandidateStatus,
+		"difference_count": len(comparison.Differences),
+	}
+
+	if comparison.Matches() {
+		gs.logger.Debug("Compare responses match", fields)
+		return
+	}
+
+	if gs.responseComparator.ShouldSample(compare.SampleRate) {
+		fields["sampled"] = true
+		fields["differences"] = comparison.Differences
+	}
+	gs.logger.Warn("⚖️ Compare response mismatch", fields)
+}
+
+// responseBodyBytes returns the raw bytes of a converted response body
+func responseBodyBytes(body interface{}) []byte {
+	switch b := body.(type) {
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	default:
+		data, _ := json.Marshal(b)
+		return data
+	}
+}
+
 // handleLogicMode handles logic mode requests
 func (gs *GatewayService) handleLogicMode(ctx context.Context, reqCtx *domain.RequestContext, routeConfig ports.RouteConfig) (*domain.Response, error) {
 	// Collect service information for all upstreams
@@ -305,6 +757,12 @@ func (gs *GatewayService) handleLogicMode(ctx context.Context, reqCtx *domain.Re
 
 	result, err := gs.strategyManager.ExecuteStrategy(ctx, routeConfig.Strategy, strategyParams)
 	if err != nil {
+		if errors.Is(err, domain.ErrUnsafePath) {
+			return gs.unsafePathResponse(reqCtx, err), nil
+		}
+		if resp := gs.overloadResponse(reqCtx, err); resp != nil {
+			return resp, nil
+		}
 		gs.logger.Error("Logic strategy execution failed", err, map[string]interface{}{
 			"request_id": reqCtx.RequestID,
 			"strategy":   routeConfig.Strategy,
@@ -380,12 +838,17 @@ func (gs *GatewayService) convertUser(user *domain.User) *ports.UserInfo {
 	}
 }
 
-// convertHTTPResponse converts http.Response to domain.Response
-func (gs *GatewayService) convertHTTPResponse(httpResp *http.Response) (*domain.Response, error) {
+// convertHTTPResponse converts http.Response to domain.Response, buffering at most maxSize bytes
+func (gs *GatewayService) convertHTTPResponse(httpResp *http.Response, maxSize int64) (*domain.Response, error) {
 	defer httpResp.Body.Close()
 
+	// Fail fast when the upstream announces a body above the limit
+	if maxSize > 0 && httpResp.ContentLength > maxSize {
+		return nil, fmt.Errorf("%w (content length %d, limit %d bytes)", domain.ErrResponseTooLarge, httpResp.ContentLength, maxSize)
+	}
+
 	// Read the response body
-	bodyBytes, err := io.ReadAll(httpResp.Body)
+	bodyBytes, err := domain.ReadLimited(httpResp.Body, maxSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -491,8 +954,11 @@ func (gs *GatewayService) createHTTPRequestFromContext(reqCtx *domain.RequestCon
 		Path:   reqCtx.Path,
 	}
 
-	// Add query parameters
-	if len(reqCtx.Query) > 0 {
+	// Forward the query string as received so repeated parameters survive;
+	// requests built without one fall back to the single-valued map
+	if reqCtx.RawQuery != "" {
+		requestURL.RawQuery = reqCtx.RawQuery
+	} else if len(reqCtx.Query) > 0 {
 		values := url.Values{}
 		for key, value := range reqCtx.Query {
 			values.Add(key, value)
@@ -503,9 +969,11 @@ func (gs *GatewayService) createHTTPRequestFromContext(reqCtx *domain.RequestCon
 	// Create request body
 	var body io.Reader
 	if reqCtx.Body != nil {
-		// Check if this is raw body data (e.g., multipart/form-data)
+		// Check if this is raw body data or a streamed body (e.g., multipart/form-data)
 		if rawBody, ok := reqCtx.Body.([]byte); ok {
 			body = bytes.NewReader(rawBody)
+		} else if stream, ok := reqCtx.Body.(io.Reader); ok {
+			body = stream
 		} else {
 			// Try to marshal as JSON for other cases
 			if jsonBytes, err := json.Marshal(reqCtx.Body); err == nil {
@@ -521,6 +989,11 @@ func (gs *GatewayService) createHTTPRequestFromContext(reqCtx *domain.RequestCon
 		req, _ = http.NewRequest("GET", "/", nil)
 	}
 
+	// Streamed bodies keep the client's Content-Length instead of being sent chunked
+	if _, ok := body.(*bytes.Reader); !ok && body != nil && reqCtx.ContentLength > 0 {
+		req.ContentLength = reqCtx.ContentLength
+	}
+
 	// Add headers
 	for key, value := range reqCtx.Headers {
 		// Skip the custom header we added for raw body detection
@@ -531,12 +1004,13 @@ func (gs *GatewayService) createHTTPRequestFromContext(reqCtx *domain.RequestCon
 
 	// Set content type if body exists and no content-type is set
 	if body != nil && req.Header.Get("Content-Type") == "" {
-		// Check if this is raw body data (multipart/form-data)
-		if _, ok := reqCtx.Body.([]byte); ok {
+		// Check if this is raw or streamed body data (multipart/form-data)
+		switch reqCtx.Body.(type) {
+		case []byte, io.Reader:
 			// For raw body, we should have received the content-type from the original request
 			// If not, this is an error condition
 			req.Header.Set("Content-Type", "application/octet-stream")
-		} else {
+		default:
 			req.Header.Set("Content-Type", "application/json")
 		}
 	}
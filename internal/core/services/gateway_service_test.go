@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// localTokens is an auth service that accepts a single API key and a single
+// locally signed token, and counts the local token checks
+type localTokens struct {
+	checks int
+}
+
+func (lt *localTokens) ValidateAPIKey(_ context.Context, apiKey string) (bool, error) {
+	return apiKey == "client-key", nil
+}
+func (lt *localTokens) ValidateJWT(ctx context.Context, token string) (*ports.UserInfo, error) {
+	return lt.ValidateJWTWithPolicy(ctx, token, nil)
+}
+func (lt *localTokens) ValidateJWTWithPolicy(_ context.Context, token string, _ *ports.ClaimsPolicy) (*ports.UserInfo, error) {
+	lt.checks++
+	if token != "local-token" {
+		return nil, errors.New("signature is invalid")
+	}
+	return &ports.UserInfo{ID: "local-user", Roles: []string{"viewer"}}, nil
+}
+func (lt *localTokens) GenerateJWT(context.Context, *ports.UserInfo) (string, error) {
+	return "", errors.New("not supported")
+}
+
+func newTestGatewayService(authService ports.AuthService, localTokenCheck bool, configProvider ports.ConfigProvider) *GatewayService {
+	log := logger.NewLogger("error", "json", "test")
+	return NewGatewayService(nil, nil, authService, localTokenCheck, log, nil, configProvider, metrics.NewCollector())
+}
+
+func TestAuthenticateRequest(t *testing.T) {
+	serviceUser := &domain.User{ID: "service-user", Roles: []string{"admin"}}
+
+	tests := []struct {
+		name            string
+		localTokenCheck bool
+		user            *domain.User
+		headers         map[string]string
+		wantUser        string // empty when authentication fails
+		wantChecks      int
+	}{
+		{"middleware user is reused", false, serviceUser, map[string]string{"authorization": "Bearer foreign-token"}, "service-user", 0},
+		{"middleware user is reused in local mode", true, serviceUser, map[string]string{"authorization": "Bearer foreign-token"}, "service-user", 0},
+		{"service mode does not check tokens itself", false, nil, map[string]string{"authorization": "Bearer local-token"}, "", 0},
+		{"local mode checks the token", true, nil, map[string]string{"authorization": "Bearer local-token"}, "local-user", 1},
+		{"local mode rejects a bad token", true, nil, map[string]string{"authorization": "Bearer foreign-token"}, "", 1},
+		{"api key", false, nil, map[string]string{"x-api-key": "client-key"}, "api-key-user", 0},
+		{"no credentials", true, nil, map[string]string{}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &localTokens{}
+			gs := newTestGatewayService(authService, tt.localTokenCheck, nil)
+			reqCtx := &domain.RequestContext{RequestID: "r1", Method: "GET", Path: "/api/v1/plants", Headers: tt.headers, User: tt.user}
+
+			user, err := gs.authenticateRequest(context.Background(), reqCtx, nil)
+			switch {
+			case tt.wantUser == "" && err == nil:
+				t.Errorf("authenticateRequest() = %v, want an error", user)
+			case tt.wantUser != "" && err != nil:
+				t.Errorf("authenticateRequest() error = %v, want user %s", err, tt.wantUser)
+			case tt.wantUser != "" && user.ID != tt.wantUser:
+				t.Errorf("authenticateRequest() user = %s, want %s", user.ID, tt.wantUser)
+			}
+			if authService.checks != tt.wantChecks {
+				t.Errorf("local token checks = %d, want %d", authService.checks, tt.wantChecks)
+			}
+		})
+	}
+}
+
+func TestCreateHTTPRequestFromContextQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    map[string]string
+		rawQuery string
+		want     string
+	}{
+		{"repeated parameters are kept", map[string]string{"tag": "a"}, "tag=a&tag=b&page=2", "tag=a&tag=b&page=2"},
+		{"encoding is kept as received", map[string]string{"q": "a b"}, "q=a%20b", "q=a%20b"},
+		{"built from the map without a raw query", map[string]string{"page": "2"}, "", "page=2"},
+		{"no query", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestGatewayService(&localTokens{}, false, nil)
+			reqCtx := &domain.RequestContext{Method: "GET", Path: "/api/v1/plants", Query: tt.query, RawQuery: tt.rawQuery}
+			if got := gs.createHTTPRequestFromContext(reqCtx).URL.RawQuery; got != tt.want {
+				t.Errorf("RawQuery = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Difference describes a single structural mismatch between two JSON documents
+type Difference struct {
+	Path      string      `json:"path"`
+	Kind      string      `json:"kind"` // "missing", "extra", "type", "value"
+	Primary   interface{} `json:"primary,omitempty"`
+	Candidate interface{} `json:"candidate,omitempty"`
+}
+
+// ComparisonResult holds the outcome of comparing a primary and a candidate response
+type ComparisonResult struct {
+	PrimaryStatus   int          `json:"primary_status"`
+	CandidateStatus int          `json:"candidate_status"`
+	Differences     []Difference `json:"differences,omitempty"`
+}
+
+// Matches reports whether the two responses are considered equivalent
+func (cr *ComparisonResult) Matches() bool {
+	return cr.PrimaryStatus == cr.CandidateStatus && len(cr.Differences) == 0
+}
+
+// maxConcurrentComparisons caps the candidate calls in flight across all routes
+const maxConcurrentComparisons = 8
+
+// ResponseComparator structurally diffs JSON responses from primary and candidate upstreams
+type ResponseComparator struct {
+	maxDifferences int
+	slots          chan struct{}
+}
+
+// NewResponseComparator creates a new response comparator
+func NewResponseComparator() *ResponseComparator {
+	return &ResponseComparator{
+		maxDifferences: 50,
+		slots:          make(chan struct{}, maxConcurrentComparisons),
+	}
+}
+
+// TryAcquire reserves a slot for one candidate call. It never waits: when every
+// slot is taken the comparison is skipped so shadow traffic cannot pile up behind
+// a slow candidate.
+func (rc *ResponseComparator) TryAcquire() (release func(), ok bool) {
+	select {
+	case rc.slots <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-rc.slots }) }, true
+	default:
+		return nil, false
+	}
+}
+
+// Compare diffs two response bodies, skipping fields that match any ignore pattern.
+//
+// Ignore patterns are dot-separated paths where "*" matches any object key or array
+// index (e.g. "data.*.id"). A pattern without dots matches that key at any depth,
+// which is convenient for volatile fields such as "timestamp".
+func (rc *ResponseComparator) Compare(primaryStatus int, primaryBody []byte, candidateStatus int, candidateBody []byte, ignoreFields []string) *ComparisonResult {
+	result := &ComparisonResult{
+		PrimaryStatus:   primaryStatus,
+		CandidateStatus: candidateStatus,
+	}
+
+	var primary, candidate interface{}
+	primaryErr := json.Unmarshal(primaryBody, &primary)
+	candidateErr := json.Unmarshal(candidateBody, &candidate)
+
+	// Fall back to a raw comparison when either side is not JSON
+	if primaryErr != nil || candidateErr != nil {
+		if string(primaryBody) != string(candidateBody) {
+			result.Differences = append(result.Differences, Difference{
+				Path: "$",
+				Kind: "value",
+			})
+		}
+		return result
+	}
+
+	rc.diff(nil, primary, candidate, ignoreFields, result)
+	return result
+}
+
+// diff walks both documents recursively and records differences
+func (rc *ResponseComparator) diff(path []string, primary, candidate interface{}, ignoreFields []string, result *ComparisonResult) {
+	if len(result.Differences) >= rc.maxDifferences {
+		return
+	}
+	if len(path) > 0 && isIgnoredPath(path, ignoreFields) {
+		return
+	}
+
+	switch p := primary.(type) {
+	case map[string]interface{}:
+		c, ok := candidate.(map[string]interface{})
+		if !ok {
+			rc.record(path, "type", primary, candidate, result)
+			return
+		}
+		for _, key := range sortedKeys(p) {
+			childPath := appendPath(path, key)
+			cv, exists := c[key]
+			if !exists {
+				if !isIgnoredPath(childPath, ignoreFields) {
+					rc.record(childPath, "missing", p[key], nil, result)
+				}
+				continue
+			}
+			rc.diff(childPath, p[key], cv, ignoreFields, result)
+		}
+		for _, key := range sortedKeys(c) {
+			if _, exists := p[key]; exists {
+				continue
+			}
+			childPath := appendPath(path, key)
+			if !isIgnoredPath(childPath, ignoreFields) {
+				rc.record(childPath, "extra", nil, c[key], result)
+			}
+		}
+	case []interface{}:
+		c, ok := candidate.([]interface{})
+		if !ok {
+			rc.record(path, "type", primary, candidate, result)
+			return
+		}
+		for i := 0; i < len(p) || i < len(c); i++ {
+			childPath := appendPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(c):
+				if !isIgnoredPath(childPath, ignoreFields) {
+					rc.record(childPath, "missing", p[i], nil, result)
+				}
+			case i >= len(p):
+				if !isIgnoredPath(childPath, ignoreFields) {
+					rc.record(childPath, "extra", nil, c[i], result)
+				}
+			default:
+				rc.diff(childPath, p[i], c[i], ignoreFields, result)
+			}
+		}
+	default:
+		if fmt.Sprintf("%T", primary) != fmt.Sprintf("%T", candidate) {
+			rc.record(path, "type", primary, candidate, result)
+			return
+		}
+		if primary != candidate {
+			rc.record(path, "value", primary, candidate, result)
+		}
+	}
+}
+
+// record appends a difference unless the limit has been reached
+func (rc *ResponseComparator) record(path []string, kind string, primary, candidate interface{}, result *ComparisonResult) {
+	if len(result.Differences) >= rc.maxDifferences {
+		return
+	}
+	result.Differences = append(result.Differences, Difference{
+		Path:      formatPath(path),
+		Kind:      kind,
+		Primary:   primary,
+		Candidate: candidate,
+	})
+}
+
+// ShouldSample decides whether a mismatch should be logged in full detail
+func (rc *ResponseComparator) ShouldSample(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+// isIgnoredPath checks whether a path matches any of the ignore patterns
+func isIgnoredPath(path []string, ignoreFields []string) bool {
+	for _, pattern := range ignoreFields {
+		if !strings.Contains(pattern, ".") {
+			if pattern == path[len(path)-1] {
+				return true
+			}
+			continue
+		}
+
+		parts := strings.Split(pattern, ".")
+		if len(parts) != len(path) {
+			continue
+		}
+		matched := true
+		for i, part := range parts {
+			if part != "*" && part != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// appendPath returns a new path with the given segment appended
+func appendPath(path []string, segment string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, segment)
+}
+
+// formatPath renders a path for logs
+func formatPath(path []string) string {
+	if len(path) == 0 {
+		return "$"
+	}
+	return strings.Join(path, ".")
+}
+
+// sortedKeys returns map keys in a stable order so diffs are reproducible
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/metrics"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func TestResponseComparatorCompare(t *testing.T) {
+	tests := []struct {
+		name            string
+		primaryStatus   int
+		primary         string
+		candidateStatus int
+		candidate       string
+		ignore          []string
+		want            []Difference
+		wantMatch       bool
+	}{
+		{
+			name:      "identical documents",
+			primary:   `{"id": 1, "tags": ["a", "b"]}`,
+			candidate: `{"tags": ["a", "b"], "id": 1}`,
+			wantMatch: true,
+		},
+		{
+			name:      "changed value",
+			primary:   `{"data": {"name": "ficus"}}`,
+			candidate: `{"data": {"name": "fern"}}`,
+			want:      []Difference{{Path: "data.name", Kind: "value", Primary: "ficus", Candidate: "fern"}},
+		},
+		{
+			name:      "missing and extra fields",
+			primary:   `{"a": 1, "b": 2}`,
+			candidate: `{"b": 2, "c": 3}`,
+			want: []Difference{
+				{Path: "a", Kind: "missing", Primary: float64(1)},
+				{Path: "c", Kind: "extra", Candidate: float64(3)},
+			},
+		},
+		{
+			name:      "changed type",
+			primary:   `{"count": 1}`,
+			candidate: `{"count": "1"}`,
+			want:      []Difference{{Path: "count", Kind: "type", Primary: float64(1), Candidate: "1"}},
+		},
+		{
+			name:      "array length",
+			primary:   `[1, 2]`,
+			candidate: `[1]`,
+			want:      []Difference{{Path: "1", Kind: "missing", Primary: float64(2)}},
+		},
+		{
+			name:      "key ignored at any depth",
+			primary:   `{"timestamp": 1, "data": {"timestamp": 2, "value": 3}}`,
+			candidate: `{"timestamp": 9, "data": {"timestamp": 8, "value": 3}}`,
+			ignore:    []string{"timestamp"},
+			wantMatch: true,
+		},
+		{
+			name:      "wildcard path",
+			primary:   `{"data": [{"id": 1, "v": 1}, {"id": 2, "v": 2}]}`,
+			candidate: `{"data": [{"id": 7, "v": 1}, {"id": 8, "v": 3}]}`,
+			ignore:    []string{"data.*.id"},
+			want:      []Difference{{Path: "data.1.v", Kind: "value", Primary: float64(2), Candidate: float64(3)}},
+		},
+		{
+			name:      "wildcard path does not match other depths",
+			primary:   `{"id": 1}`,
+			candidate: `{"id": 2}`,
+			ignore:    []string{"data.*.id"},
+			want:      []Difference{{Path: "id", Kind: "value", Primary: float64(1), Candidate: float64(2)}},
+		},
+		{
+			name:      "ignored missing field",
+			primary:   `{"generated_at": "now", "v": 1}`,
+			candidate: `{"v": 1}`,
+			ignore:    []string{"generated_at"},
+			wantMatch: true,
+		},
+		{
+			name:      "identical non-JSON bodies",
+			primary:   "plain text",
+			candidate: "plain text",
+			wantMatch: true,
+		},
+		{
+			name:      "non-JSON bodies differ",
+			primary:   "plain text",
+			candidate: "other text",
+			want:      []Difference{{Path: "$", Kind: "value"}},
+		},
+		{
+			name:      "one side not JSON",
+			primary:   `{"v": 1}`,
+			candidate: "<html>error</html>",
+			want:      []Difference{{Path: "$", Kind: "value"}},
+		},
+		{
+			name:            "status differs with equal bodies",
+			primaryStatus:   200,
+			primary:         `{}`,
+			candidateStatus: 500,
+			candidate:       `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResponseComparator().Compare(tt.primaryStatus, []byte(tt.primary), tt.candidateStatus, []byte(tt.candidate), tt.ignore)
+			if !reflect.DeepEqual(result.Differences, tt.want) {
+				t.Errorf("differences = %+v, want %+v", result.Differences, tt.want)
+			}
+			if result.Matches() != tt.wantMatch {
+				t.Errorf("Matches() = %v, want %v", result.Matches(), tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestResponseComparatorLimitsDifferences(t *testing.T) {
+	comparator := NewResponseComparator()
+	comparator.maxDifferences = 2
+	result := comparator.Compare(200, []byte(`[1, 2, 3, 4]`), 200, []byte(`[5, 6, 7, 8]`), nil)
+	if len(result.Differences) != 2 {
+		t.Errorf("differences = %d, want 2", len(result.Differences))
+	}
+}
+
+func TestResponseComparatorTryAcquire(t *testing.T) {
+	comparator := NewResponseComparator()
+	releases := make([]func(), 0, maxConcurrentComparisons)
+	for i := 0; i < maxConcurrentComparisons; i++ {
+		release, ok := comparator.TryAcquire()
+		if !ok {
+			t.Fatalf("slot %d refused", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := comparator.TryAcquire(); ok {
+		t.Fatal("a comparison started above the limit")
+	}
+
+	// Releasing twice frees a single slot
+	releases[0]()
+	releases[0]()
+	if _, ok := comparator.TryAcquire(); !ok {
+		t.Fatal("slot not freed by release")
+	}
+	if _, ok := comparator.TryAcquire(); ok {
+		t.Error("a double release freed two slots")
+	}
+}
+
+func TestStartComparisonSkipsWhenBusy(t *testing.T) {
+	gs := newTestGatewayService(&localTokens{}, false, nil)
+	for i := 0; i < maxConcurrentComparisons; i++ {
+		if _, ok := gs.responseComparator.TryAcquire(); !ok {
+			t.Fatalf("slot %d refused", i)
+		}
+	}
+
+	route := ports.RouteConfig{Method: "GET", Path: "/api/v1/plants", Compare: &ports.CompareConfig{Candidate: "plants_v2"}}
+	gs.startComparison(&domain.RequestContext{RequestID: "r1", Method: "GET"}, route, &domain.Response{StatusCode: 200})
+	gs.startComparison(&domain.RequestContext{RequestID: "r2", Method: "POST"}, route, &domain.Response{StatusCode: 200})
+
+	var skipped float64
+	for _, counter := range gs.metrics.(*metrics.Collector).Snapshot().Counters {
+		if counter.Name == "gateway_compare_skipped_total" {
+			skipped += counter.Value
+		}
+	}
+	if skipped != 1 {
+		t.Errorf("skipped comparisons = %v, want 1 (unsafe methods are never compared)", skipped)
+	}
+}
@@ -1,7 +1,6 @@
 package strategies
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,9 +9,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
 )
 
+// maxErrorBodySize caps how much of an upstream error body is kept for error messages
+const maxErrorBodySize = 4 << 10
+
 // serviceCall represents a service call configuration for orchestration
 type serviceCall struct {
 	service  string
@@ -48,17 +51,14 @@ func (ps *ProxyStrategy) Execute(ctx context.Context, params ports.StrategyParam
 	}
 
 	// Build target URL
-	targetPath := routeConfig.TargetPath
-	if targetPath == "" {
-		targetPath = params.Request.URL.Path
+	targetPath, rawQuery, err := rewriteRule(routeConfig).Rewrite(routeConfig.Path, params.Request.URL.Path, params.Request.URL.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewrite request path: %w", err)
 	}
 
-	// Replace path parameters
-	targetPath = ps.replacePathParameters(targetPath, params.Request.URL.Path, routeConfig.Path)
-
 	targetURL := serviceInfo.URL + targetPath
-	if params.Request.URL.RawQuery != "" {
-		targetURL += "?" + params.Request.URL.RawQuery
+	if rawQuery != "" {
+		targetURL += "?" + rawQuery
 	}
 
 	params.Logger.Info("🌐 Proxying request", map[string]interface{}{
@@ -67,20 +67,19 @@ func (ps *ProxyStrategy) Execute(ctx context.Context, params ports.StrategyParam
 		"method":       params.Request.Method,
 	})
 
-	// Create new request
+	// Create new request, streaming the incoming body instead of buffering it
 	var body io.Reader
-	if params.Request.Body != nil {
-		bodyBytes, err := io.ReadAll(params.Request.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read request body: %w", err)
-		}
-		body = bytes.NewReader(bodyBytes)
+	if params.Request.Body != nil && params.Request.Body != http.NoBody {
+		body = params.Request.Body
 	}
 
 	req, err := http.NewRequestWithContext(ctx, params.Request.Method, targetURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy request: %w", err)
 	}
+	if body != nil {
+		req.ContentLength = params.Request.ContentLength
+	}
 
 	// Copy headers (excluding host and hop-by-hop headers)
 	for name, values := range params.Request.Header {
@@ -99,7 +98,7 @@ func (ps *ProxyStrategy) Execute(ctx context.Context, params ports.StrategyParam
 		}
 	}
 
-	client := &http.Client{Timeout: timeout}
+	client := &http.Client{Timeout: timeout, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		params.Logger.Error("❌ Proxy request failed", err, map[string]interface{}{
@@ -134,52 +133,16 @@ func (ps *ProxyStrategy) Execute(ctx context.Context, params ports.StrategyParam
 	return resp, nil
 }
 
-// replacePathParameters replaces path parameters in target path
-func (ps *ProxyStrategy) replacePathParameters(targetPath, requestPath, routePath string) string {
-	routeParts := strings.Split(strings.Trim(routePath, "/"), "/")
-	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
-
-	// Handle wildcard routes (ending with *)
-	if len(routeParts) > 0 && routeParts[len(routeParts)-1] == "*" {
-		routePrefix := routeParts[:len(routeParts)-1]
-		if len(requestParts) >= len(routePrefix) {
-			// Replace parameters in the prefix
-			result := targetPath
-			for i, routePart := range routePrefix {
-				if strings.HasPrefix(routePart, "{") && strings.HasSuffix(routePart, "}") {
-					paramValue := requestParts[i]
-					result = strings.ReplaceAll(result, routePart, paramValue)
-				}
-			}
-
-			// Handle wildcard (*) in target path
-			if strings.Contains(result, "*") {
-				// Extract the remaining path after the prefix
-				remainingParts := requestParts[len(routePrefix):]
-				remainingPath := strings.Join(remainingParts, "/")
-				result = strings.ReplaceAll(result, "*", remainingPath)
-			}
-
-			return result
-		}
-		return targetPath
+// rewriteRule builds the rewrite rule of a route from its target path and rewrite settings
+func rewriteRule(routeConfig ports.RouteConfig) domain.RewriteRule {
+	rule := domain.RewriteRule{TargetPath: routeConfig.TargetPath}
+	if routeConfig.Rewrite != nil {
+		rule.StripPrefix = routeConfig.Rewrite.StripPrefix
+		rule.Regex = routeConfig.Rewrite.Regex
+		rule.Replacement = routeConfig.Rewrite.Replacement
+		rule.Query = routeConfig.Rewrite.Query
 	}
-
-	// Handle exact match routes
-	if len(routeParts) != len(requestParts) {
-		return targetPath
-	}
-
-	result := targetPath
-	for i, routePart := range routeParts {
-		if strings.HasPrefix(routePart, "{") && strings.HasSuffix(routePart, "}") {
-			paramName := routePart
-			paramValue := requestParts[i]
-			result = strings.ReplaceAll(result, paramName, paramValue)
-		}
-	}
-
-	return result
+	return rule
 }
 
 // shouldForwardHeader determines if a header should be forwarded
@@ -314,7 +277,7 @@ func (dos *DashboardOrchestratorStrategy) callService(ctx context.Context, upstr
 		req.Header.Set("X-User-Email", params.UserInfo.Email)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -325,7 +288,7 @@ func (dos *DashboardOrchestratorStrategy) callService(ctx context.Context, upstr
 		return nil, fmt.Errorf("service returned error status: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -363,6 +326,9 @@ func (upos *UserProfileOrchestratorStrategy) Execute(ctx context.Context, params
 	if userID == "" {
 		return nil, fmt.Errorf("user ID not found")
 	}
+	if _, err := domain.EscapePathSegment(userID); err != nil {
+		return nil, err
+	}
 
 	params.Logger.Info("🔍 Fetching user profile", map[string]interface{}{
 		"user_id": userID,
@@ -469,7 +435,11 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserInfo(ctx context.Context,
 		return nil, fmt.Errorf("auth service not configured")
 	}
 
-	targetURL := fmt.Sprintf("%s/api/v1/users/%s", serviceInfo.URL, userID)
+	targetPath, err := domain.ExpandPathTemplate("/api/v1/users/{user_id}", map[string]string{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	targetURL := serviceInfo.URL + targetPath
 
 	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
@@ -481,7 +451,7 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserInfo(ctx context.Context,
 		req.Header.Set("Authorization", authHeader)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -489,11 +459,11 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserInfo(ctx context.Context,
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("auth service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -513,7 +483,11 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserPlants(ctx context.Context
 		return nil, fmt.Errorf("plant_management service not configured")
 	}
 
-	targetURL := fmt.Sprintf("%s/api/v1/plants/users/%s", serviceInfo.URL, userID)
+	targetPath, err := domain.ExpandPathTemplate("/api/v1/plants/users/{user_id}", map[string]string{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	targetURL := serviceInfo.URL + targetPath
 
 	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
@@ -525,7 +499,7 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserPlants(ctx context.Context
 		req.Header.Set("Authorization", authHeader)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -538,11 +512,11 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserPlants(ctx context.Context
 	}
 
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("plant service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -562,7 +536,11 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserDevices(ctx context.Contex
 		return nil, fmt.Errorf("plant_management service not configured")
 	}
 
-	targetURL := fmt.Sprintf("%s/api/v1/devices/users/%s", serviceInfo.URL, userID)
+	targetPath, err := domain.ExpandPathTemplate("/api/v1/devices/users/{user_id}", map[string]string{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	targetURL := serviceInfo.URL + targetPath
 
 	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
@@ -574,7 +552,7 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserDevices(ctx context.Contex
 		req.Header.Set("Authorization", authHeader)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -587,11 +565,11 @@ func (upos *UserProfileOrchestratorStrategy) fetchUserDevices(ctx context.Contex
 	}
 
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 		return nil, fmt.Errorf("device service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -643,7 +621,8 @@ func (pfrs *PlantFullReportStrategy) GetName() string {
 // Execute executes the plant full report strategy
 func (pfrs *PlantFullReportStrategy) Execute(ctx context.Context, params ports.StrategyParams) (interface{}, error) {
 	// Extract plant ID from request path
-	plantID := pfrs.extractPlantID(params.Request.URL.Path)
+	pathParams, _ := domain.MatchPath(params.RouteConfig.Path, params.Request.URL.Path)
+	plantID := pathParams["id"]
 	if plantID == "" {
 		return nil, fmt.Errorf("plant ID not found in path")
 	}
@@ -661,7 +640,10 @@ func (pfrs *PlantFullReportStrategy) Execute(ctx context.Context, params ports.S
 	// Prepare service calls with plant ID
 	calls := []serviceCall{}
 	for _, upstream := range params.RouteConfig.Upstreams {
-		endpoint := strings.ReplaceAll(upstream.Endpoint, "{id}", plantID)
+		endpoint, err := domain.ExpandPathTemplate(upstream.Endpoint, pathParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plant endpoint %s: %w", upstream.Endpoint, err)
+		}
 		calls = append(calls, serviceCall{
 			service:  upstream.Service,
 			endpoint: endpoint,
@@ -728,17 +710,6 @@ func (pfrs *PlantFullReportStrategy) Execute(ctx context.Context, params ports.S
 	return report, nil
 }
 
-// extractPlantID extracts plant ID from the request path
-func (pfrs *PlantFullReportStrategy) extractPlantID(path string) string {
-	parts := strings.Split(strings.Trim(path, "/"), "/")
-	for i, part := range parts {
-		if part == "plant" && i+1 < len(parts) {
-			return parts[i+1]
-		}
-	}
-	return ""
-}
-
 // callServiceForPlant makes a service call for plant-specific data
 func (pfrs *PlantFullReportStrategy) callServiceForPlant(ctx context.Context, call serviceCall, plantID string, params ports.StrategyParams) (interface{}, error) {
 	serviceInfo, exists := params.Services[call.service]
@@ -764,7 +735,7 @@ func (pfrs *PlantFullReportStrategy) callServiceForPlant(ctx context.Context, ca
 	}
 	req.Header.Set("X-Plant-ID", plantID)
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -775,7 +746,7 @@ func (pfrs *PlantFullReportStrategy) callServiceForPlant(ctx context.Context, ca
 		return nil, fmt.Errorf("service returned error status: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
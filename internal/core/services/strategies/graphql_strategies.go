@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/domain"
 	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
 )
 
@@ -198,7 +199,7 @@ func (lss *LocalSchemaStrategy) forwardGraphQLRequest(ctx context.Context, reque
 		}
 	}
 
-	client := &http.Client{Timeout: timeout}
+	client := &http.Client{Timeout: timeout, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		params.Logger.Error("❌ GraphQL request failed", err, map[string]interface{}{
@@ -219,7 +220,7 @@ func (lss *LocalSchemaStrategy) forwardGraphQLRequest(ctx context.Context, reque
 	})
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		params.Logger.Error("❌ Failed to read GraphQL response body", err, map[string]interface{}{
 			"target_url": targetURL,
@@ -366,11 +367,11 @@ func (gps *GraphQLProxyStrategy) Execute(ctx context.Context, params ports.Strat
 		targetURL = serviceInfo.URL + "/graphql"
 	}
 
-	return gps.forwardRequest(ctx, gqlRequest, targetURL, params)
+	return gps.forwardRequest(ctx, gqlRequest, targetURL, serviceInfo, params)
 }
 
 // forwardRequest forwards the GraphQL request to upstream
-func (gps *GraphQLProxyStrategy) forwardRequest(ctx context.Context, request GraphQLRequest, targetURL string, params ports.StrategyParams) (interface{}, error) {
+func (gps *GraphQLProxyStrategy) forwardRequest(ctx context.Context, request GraphQLRequest, targetURL string, serviceInfo ports.ServiceInfo, params ports.StrategyParams) (interface{}, error) {
 	// Serialize request
 	requestBody, err := json.Marshal(request)
 	if err != nil {
@@ -396,7 +397,7 @@ func (gps *GraphQLProxyStrategy) forwardRequest(ctx context.Context, request Gra
 		req.Header.Set("X-User-Email", params.UserInfo.Email)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: serviceInfo.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("GraphQL proxy request failed: %w", err)
@@ -404,7 +405,7 @@ func (gps *GraphQLProxyStrategy) forwardRequest(ctx context.Context, request Gra
 	defer resp.Body.Close()
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	body, err := domain.ReadLimited(resp.Body, serviceInfo.ResponseLimit(params.RouteConfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read GraphQL response: %w", err)
 	}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+// Variant selection reasons reported in logs and metrics
+const (
+	SelectionForced   = "forced"
+	SelectionSticky   = "sticky"
+	SelectionWeighted = "weighted"
+)
+
+// TrafficSplitter selects an upstream variant for routes with weighted traffic splitting
+type TrafficSplitter struct {
+	// overrides holds runtime weights set through the admin API, keyed by route key
+	overrides map[string]map[string]int
+	mutex     sync.RWMutex
+	logger    ports.Logger
+}
+
+// NewTrafficSplitter creates a new traffic splitter
+func NewTrafficSplitter(logger ports.Logger) *TrafficSplitter {
+	return &TrafficSplitter{
+		overrides: make(map[string]map[string]int),
+		logger:    logger,
+	}
+}
+
+// RouteKey builds the key used to identify a route for weight overrides
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// SelectVariant picks the variant that should serve a request.
+//
+// A variant can be forced through the configured header or cookie, either by
+// name or with "true"/"false" to select the canary or the stable variant. When
+// sticky is enabled, authenticated users are hashed onto a variant so they keep
+// seeing the same version; everyone else is distributed by weight.
+func (ts *TrafficSplitter) SelectVariant(routeKey string, split *ports.TrafficSplitConfig, headers map[string]string, userID string) (ports.TrafficVariant, string) {
+	if forced, ok := ts.forcedVariant(split, headers); ok {
+		return forced, SelectionForced
+	}
+
+	variants := ts.effectiveVariants(routeKey, split.Variants)
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return variants[0], SelectionWeighted
+	}
+
+	var point int
+	reason := SelectionWeighted
+	if split.Sticky && userID != "" {
+		hasher := fnv.New32a()
+		hasher.Write([]byte(routeKey + "|" + userID))
+		point = int(hasher.Sum32() % uint32(total))
+		reason = SelectionSticky
+	} else {
+		point = rand.Intn(total)
+	}
+
+	for _, variant := range variants {
+		if point < variant.Weight {
+			return variant, reason
+		}
+		point -= variant.Weight
+	}
+	return variants[len(variants)-1], reason
+}
+
+// forcedVariant resolves a variant requested explicitly via header or cookie
+func (ts *TrafficSplitter) forcedVariant(split *ports.TrafficSplitConfig, headers map[string]string) (ports.TrafficVariant, bool) {
+	value := ""
+	if split.Header != "" {
+		value = headers[strings.ToLower(split.Header)]
+	}
+	if value == "" && split.Cookie != "" {
+		if cookieHeader, exists := headers["cookie"]; exists {
+			req := &http.Request{Header: http.Header{"Cookie": []string{cookieHeader}}}
+			if cookie, err := req.Cookie(split.Cookie); err == nil {
+				value = cookie.Value
+			}
+		}
+	}
+	if value == "" {
+		return ports.TrafficVariant{}, false
+	}
+
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, variant := range split.Variants {
+		if strings.ToLower(variant.Name) == value {
+			return variant, true
+		}
+	}
+	switch value {
+	case "true", "1", "yes":
+		for _, variant := range split.Variants {
+			if variant.Canary {
+				return variant, true
+			}
+		}
+	case "false", "0", "no":
+		for _, variant := range split.Variants {
+			if !variant.Canary {
+				return variant, true
+			}
+		}
+	}
+	return ports.TrafficVariant{}, false
+}
+
+// effectiveVariants applies runtime weight overrides to the configured variants
+func (ts *TrafficSplitter) effectiveVariants(routeKey string, variants []ports.TrafficVariant) []ports.TrafficVariant {
+	ts.mutex.RLock()
+	overrides, exists := ts.overrides[routeKey]
+	ts.mutex.RUnlock()
+	if !exists {
+		return variants
+	}
+
+	result := make([]ports.TrafficVariant, len(variants))
+	for i, variant := range variants {
+		if weight, ok := overrides[variant.Name]; ok {
+			variant.Weight = weight
+		}
+		result[i] = variant
+	}
+	return result
+}
+
+// SetWeights overrides the weights of a route's variants at runtime
+func (ts *TrafficSplitter) SetWeights(routeKey string, split *ports.TrafficSplitConfig, weights map[string]int) error {
+	known := make(map[string]bool, len(split.Variants))
+	for _, variant := range split.Variants {
+		known[variant.Name] = true
+	}
+	for name, weight := range weights {
+		if !known[name] {
+			return fmt.Errorf("unknown variant: %s", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight for variant %s cannot be negative", name)
+		}
+	}
+
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	overrides := make(map[string]int, len(weights))
+	for name, weight := range weights {
+		overrides[name] = weight
+	}
+	ts.overrides[routeKey] = overrides
+
+	ts.logger.Info("Traffic split weights updated", map[string]interface{}{
+		"route":   routeKey,
+		"weights": overrides,
+	})
+	return nil
+}
+
+// Weights returns the effective weights of a route's variants
+func (ts *TrafficSplitter) Weights(routeKey string, split *ports.TrafficSplitConfig) map[string]int {
+	weights := make(map[string]int, len(split.Variants))
+	for _, variant := range ts.effectiveVariants(routeKey, split.Variants) {
+		weights[variant.Name] = variant.Weight
+	}
+	return weights
+}
+
+// ResetOverrides drops all runtime weight overrides, e.g. after a config reload
+func (ts *TrafficSplitter) ResetOverrides() {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	ts.overrides = make(map[string]map[string]int)
+}
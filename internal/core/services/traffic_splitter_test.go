@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/adapters/logger"
+	"github.com/swarch-2f-rootly/rootly-apigateway/internal/core/ports"
+)
+
+func testSplit(sticky bool, stableWeight, canaryWeight int) *ports.TrafficSplitConfig {
+	return &ports.TrafficSplitConfig{
+		Header: "X-Canary",
+		Cookie: "canary",
+		Sticky: sticky,
+		Variants: []ports.TrafficVariant{
+			{Name: "stable", Upstream: "plants", Weight: stableWeight},
+			{Name: "canary", Upstream: "plants_v2", Weight: canaryWeight, Canary: true},
+		},
+	}
+}
+
+func TestTrafficSplitterForcedVariant(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		want       string
+		wantReason string
+	}{
+		{"variant named in the header", map[string]string{"x-canary": "Canary"}, "canary", SelectionForced},
+		{"header asks for the canary", map[string]string{"x-canary": "true"}, "canary", SelectionForced},
+		{"header asks for the stable variant", map[string]string{"x-canary": "no"}, "stable", SelectionForced},
+		{"cookie", map[string]string{"cookie": "session=abc; canary=1"}, "canary", SelectionForced},
+		{"header wins over the cookie", map[string]string{"x-canary": "stable", "cookie": "canary=canary"}, "stable", SelectionForced},
+		{"unknown value falls back to weights", map[string]string{"x-canary": "beta"}, "stable", SelectionWeighted},
+		{"nothing forced", map[string]string{}, "stable", SelectionWeighted},
+	}
+
+	splitter := NewTrafficSplitter(logger.NewLogger("error", "json", "test"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// All weight on the stable variant, so only forcing reaches the canary
+			variant, reason := splitter.SelectVariant("GET /api/v1/plants", testSplit(false, 100, 0), tt.headers, "")
+			if variant.Name != tt.want || reason != tt.wantReason {
+				t.Errorf("SelectVariant() = %s (%s), want %s (%s)", variant.Name, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTrafficSplitterStickyHashing(t *testing.T) {
+	splitter := NewTrafficSplitter(logger.NewLogger("error", "json", "test"))
+	split := testSplit(true, 50, 50)
+
+	// Every user keeps their variant, and users are spread across both
+	seen := map[string]int{}
+	for i := 0; i < 200; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		first, reason := splitter.SelectVariant("GET /api/v1/plants", split, nil, user)
+		if reason != SelectionSticky {
+			t.Fatalf("reason = %s, want %s", reason, SelectionSticky)
+		}
+		for j := 0; j < 5; j++ {
+			if again, _ := splitter.SelectVariant("GET /api/v1/plants", split, nil, user); again.Name != first.Name {
+				t.Fatalf("%s moved from %s to %s", user, first.Name, again.Name)
+			}
+		}
+		seen[first.Name]++
+	}
+	if seen["stable"] == 0 || seen["canary"] == 0 {
+		t.Errorf("users per variant = %v, want both variants used", seen)
+	}
+
+	// Anonymous callers and non-sticky routes are split by weight
+	if _, reason := splitter.SelectVariant("GET /api/v1/plants", split, nil, ""); reason != SelectionWeighted {
+		t.Errorf("anonymous reason = %s, want %s", reason, SelectionWeighted)
+	}
+	if _, reason := splitter.SelectVariant("GET /api/v1/plants", testSplit(false, 50, 50), nil, "user-1"); reason != SelectionWeighted {
+		t.Errorf("non-sticky reason = %s, want %s", reason, SelectionWeighted)
+	}
+}
+
+func TestTrafficSplitterWeights(t *testing.T) {
+	splitter := NewTrafficSplitter(logger.NewLogger("error", "json", "test"))
+	split := testSplit(false, 90, 10)
+
+	counts := map[string]int{}
+	const requests = 10000
+	for i := 0; i < requests; i++ {
+		variant, _ := splitter.SelectVariant("GET /api/v1/plants", split, nil, "")
+		counts[variant.Name]++
+	}
+	if share := float64(counts["canary"]) / requests; math.Abs(share-0.1) > 0.03 {
+		t.Errorf("canary share = %.3f, want about 0.1", share)
+	}
+
+	// Zero total weight still serves the first variant
+	if variant, _ := splitter.SelectVariant("GET /api/v1/plants", testSplit(false, 0, 0), nil, ""); variant.Name != "stable" {
+		t.Errorf("zero weights chose %s, want stable", variant.Name)
+	}
+}
+
+func TestTrafficSplitterOverrides(t *testing.T) {
+	splitter := NewTrafficSplitter(logger.NewLogger("error", "json", "test"))
+	split := testSplit(false, 100, 0)
+	routeKey := RouteKey("get", "/api/v1/plants")
+
+	if err := splitter.SetWeights(routeKey, split, map[string]int{"beta": 10}); err == nil {
+		t.Error("SetWeights() accepted an unknown variant")
+	}
+	if err := splitter.SetWeights(routeKey, split, map[string]int{"canary": -1}); err == nil {
+		t.Error("SetWeights() accepted a negative weight")
+	}
+	if err := splitter.SetWeights(routeKey, split, map[string]int{"stable": 0, "canary": 100}); err != nil {
+		t.Fatalf("SetWeights() error = %v", err)
+	}
+
+	if got := splitter.Weights(routeKey, split); !reflect.DeepEqual(got, map[string]int{"stable": 0, "canary": 100}) {
+		t.Errorf("Weights() = %v after override", got)
+	}
+	if variant, _ := splitter.SelectVariant(routeKey, split, nil, ""); variant.Name != "canary" {
+		t.Errorf("SelectVariant() = %s, want the overridden canary", variant.Name)
+	}
+	if got := splitter.Weights("GET /api/v1/other", split); got["stable"] != 100 {
+		t.Errorf("override leaked to another route: %v", got)
+	}
+
+	splitter.ResetOverrides()
+	if got := splitter.Weights(routeKey, split); !reflect.DeepEqual(got, map[string]int{"stable": 100, "canary": 0}) {
+		t.Errorf("Weights() = %v after reset, want the configured weights", got)
+	}
+}